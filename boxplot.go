@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cespare/stats/summary"
+)
+
+func boxplot(args []string) {
+	fs := flag.NewFlagSet("boxplot", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s boxplot [flags] [file ...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Draw a box plot for each input (use - for stdin), or with -groupby, for each group.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	methodStr := fs.String("quantile-method", "nearest", quantileMethodUsage)
+	k := fs.Float64("whisker", 1.5, "The multiple of the interquartile range beyond the quartiles past which values are outliers")
+	violin := fs.Bool("violin", false, "Draw the density of each input alongside a vertical box plot")
+	width := fs.Int("width", histBlocks, "The width of the plot, in columns (with -violin, of each input)")
+	height := fs.Int("height", 20, "With -violin, the height of the plot, in rows")
+	formatStr := fs.String("format", "table", "Output format: table (a plot), json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.StringVar(&inOpts.key, "groupby", "", "Draw a box plot for each distinct value of this field")
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	method, err := parseQuantileMethod(*methodStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *k < 0 {
+		log.Fatalf("-whisker must not be negative; got %g", *k)
+	}
+	if *width < 10 || *height < 5 {
+		log.Fatal("-width must be at least 10 and -height at least 5")
+	}
+	if *violin && format != formatTable {
+		log.Fatal("-violin cannot be used with -format")
+	}
+	if inOpts.key == "" && inOpts.hasGroup("key") {
+		inOpts.key = "key"
+	}
+	if inOpts.key != "" && inOpts.field == "" && inOpts.re == nil {
+		log.Fatal("-groupby requires -field")
+	}
+
+	newSummarizer := func() *summary.Summarizer {
+		sr := summary.New()
+		sr.SetQuantileMethod(method)
+		return sr
+	}
+	var (
+		names []string
+		srs   []*summary.Summarizer
+		u     unit
+	)
+	read := func(files []string, add func(nr *numberReader)) {
+		nr, err := newNumberReader(inOpts, files)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for nr.Scan() {
+			add(nr)
+		}
+		if err := nr.Err(); err != nil {
+			log.Fatal(err)
+		}
+		nr.warn()
+		if nr.Unit() != unitNone {
+			u = nr.Unit()
+		}
+	}
+	if inOpts.key != "" {
+		groups := make(map[string]*summary.Summarizer)
+		read(fs.Args(), func(nr *numberReader) {
+			sr, ok := groups[nr.Key()]
+			if !ok {
+				sr = newSummarizer()
+				groups[nr.Key()] = sr
+			}
+			sr.AddWeighted(nr.Value(), nr.Weight())
+		})
+		for key := range groups {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, key := range names {
+			srs = append(srs, groups[key])
+		}
+	} else {
+		names = fs.Args()
+		if len(names) == 0 {
+			names = []string{"-"}
+		}
+		for _, name := range names {
+			sr := newSummarizer()
+			read([]string{name}, func(nr *numberReader) {
+				sr.AddWeighted(nr.Value(), nr.Weight())
+			})
+			if sr.Count() == 0 {
+				log.Fatalf("no numbers given in %s", name)
+			}
+			srs = append(srs, sr)
+		}
+	}
+	if len(srs) == 0 {
+		log.Println("no numbers given")
+		return
+	}
+
+	boxes := make([]summary.Box, len(srs))
+	for i, sr := range srs {
+		boxes[i] = sr.BoxPlot(*k)
+	}
+	switch {
+	case format != formatTable:
+		err = writeRows(os.Stdout, format, boxRows(names, srs, boxes, u))
+	case *violin:
+		_, err = fmt.Fprintln(os.Stdout, formatViolins(names, srs, boxes, u, *width, *height))
+	default:
+		_, err = fmt.Fprintln(os.Stdout, formatBoxes(names, srs, boxes, u, *width))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// boxRows gives the values drawn by each box plot for the machine-readable
+// formats.
+func boxRows(names []string, srs []*summary.Summarizer, boxes []summary.Box, u unit) [][]stat {
+	rows := make([][]stat, len(boxes))
+	for i, b := range boxes {
+		var below, above float64
+		for _, v := range b.Outliers {
+			w := srs[i].Rank(v) - srs[i].Rank(math.Nextafter(v, math.Inf(-1)))
+			if v < b.Q1 {
+				below += w
+			} else {
+				above += w
+			}
+		}
+		rows[i] = []stat{
+			{"name", "name", names[i]},
+			{"count", "count", weightValue(srs[i].Weight())},
+			{"whisker_lo", "whisker low", unitValue(b.WhiskerLo, u)},
+			{"q1", "first quartile", unitValue(b.Q1, u)},
+			{"median", "median", unitValue(b.Median, u)},
+			{"q3", "third quartile", unitValue(b.Q3, u)},
+			{"whisker_hi", "whisker high", unitValue(b.WhiskerHi, u)},
+			{"outliers_lo", "outliers below", weightValue(below)},
+			{"outliers_hi", "outliers above", weightValue(above)},
+		}
+	}
+	return rows
+}
+
+// boxRange gives the range of values covered by all the inputs, widened
+// if it is empty so that positions within it are defined.
+func boxRange(srs []*summary.Summarizer) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, sr := range srs {
+		lo = math.Min(lo, sr.Min())
+		hi = math.Max(hi, sr.Max())
+	}
+	if lo == hi {
+		lo--
+		hi++
+	}
+	return lo, hi
+}
+
+// formatBoxes draws horizontal box plots, three rows high, on a shared axis
+// that is width columns wide. The whiskers reach the lowest and highest
+// values that are not outliers, and each outlier is a dot.
+func formatBoxes(names []string, srs []*summary.Summarizer, boxes []summary.Box, u unit, width int) string {
+	lo, hi := boxRange(srs)
+	// col gives the column of v. The boxes of nonempty inputs have no NaN
+	// values, and their values all lie within [lo, hi].
+	col := func(v float64) int {
+		c := int(math.Round((v - lo) / (hi - lo) * float64(width-1)))
+		if c < 0 {
+			return 0
+		}
+		if c >= width {
+			return width - 1
+		}
+		return c
+	}
+	labelWidth := 0
+	for _, name := range names {
+		if n := utf8.RuneCountInString(name); n > labelWidth {
+			labelWidth = n
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, b := range boxes {
+		var rows [3][]rune
+		for j := range rows {
+			rows[j] = []rune(strings.Repeat(" ", width))
+		}
+		top, mid, bottom := rows[0], rows[1], rows[2]
+		wlo, q1, med, q3, whi := col(b.WhiskerLo), col(b.Q1), col(b.Median), col(b.Q3), col(b.WhiskerHi)
+		for c := wlo; c <= whi; c++ {
+			mid[c] = '─'
+		}
+		for c := q1; c <= q3; c++ {
+			top[c], mid[c], bottom[c] = '─', ' ', '─'
+		}
+		mid[wlo], mid[whi] = '├', '┤'
+		top[q1], mid[q1], bottom[q1] = '┌', '│', '└'
+		top[q3], mid[q3], bottom[q3] = '┐', '│', '┘'
+		if wlo < q1 {
+			mid[q1] = '┤'
+		}
+		if whi > q3 {
+			mid[q3] = '├'
+		}
+		top[med], mid[med], bottom[med] = '┬', '│', '┴'
+		for _, v := range b.Outliers {
+			mid[col(v)] = '•'
+		}
+		for j, row := range rows {
+			label := ""
+			if j == 1 {
+				label = names[i]
+			}
+			fmt.Fprintf(&buf, " %-*s │%s\n", labelWidth, label, strings.TrimRight(string(row), " "))
+		}
+	}
+	writeAxis(&buf, labelWidth, lo, hi, u, width)
+	return buf.String()
+}
+
+// formatViolins draws a violin plot for each input, side by side on a
+// shared vertical axis that is height rows tall: a bar of the density of
+// the values in each row, width columns wide at most, beside a vertical box
+// plot (┃ for the box, │ for the whiskers, ━ at the median, and • for
+// outliers).
+func formatViolins(names []string, srs []*summary.Summarizer, boxes []summary.Box, u unit, width, height int) string {
+	lo, hi := boxRange(srs)
+	bounds := summary.LinearBounds(lo, hi, height)
+	// row gives the row of the plot, counting from the bottom, that holds
+	// v: the one whose bucket of the histograms it falls in.
+	row := func(v float64) int {
+		r := sort.SearchFloat64s(bounds, v)
+		if r < len(bounds) && bounds[r] == v {
+			r++
+		}
+		r--
+		if r < 0 {
+			return 0
+		}
+		if r >= height {
+			return height - 1
+		}
+		return r
+	}
+	cols := make([][]string, len(srs))
+	for i, sr := range srs {
+		h := sr.HistogramBounds(bounds)
+		var max float64
+		for _, bk := range h.Buckets {
+			max = math.Max(max, bk.Count)
+		}
+		b := boxes[i]
+		outliers := make(map[int]bool)
+		for _, v := range b.Outliers {
+			outliers[row(v)] = true
+		}
+		cols[i] = make([]string, height)
+		for r, bk := range h.Buckets {
+			marker := ' '
+			switch {
+			case r == row(b.Median):
+				marker = '━'
+			case r >= row(b.Q1) && r <= row(b.Q3):
+				marker = '┃'
+			case r >= row(b.WhiskerLo) && r <= row(b.WhiskerHi):
+				marker = '│'
+			case outliers[r]:
+				marker = '•'
+			}
+			cols[i][r] = string(marker) + bar(bk.Count/max*float64(width))
+		}
+	}
+
+	top, bottom := formatBound(hi, u), formatBound(lo, u)
+	labelWidth := utf8.RuneCountInString(top)
+	if n := utf8.RuneCountInString(bottom); n > labelWidth {
+		labelWidth = n
+	}
+	colWidth := width + 3 // the marker, the bar, and a gap
+	pad := func(s string, n int) string {
+		if k := utf8.RuneCountInString(s); k < n {
+			return s + strings.Repeat(" ", n-k)
+		}
+		return string([]rune(s)[:n])
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, " %*s  ", labelWidth, "")
+	var header string
+	for _, name := range names {
+		header += pad(name, colWidth)
+	}
+	fmt.Fprintf(&buf, "%s\n", strings.TrimRight(header, " "))
+	for r := height - 1; r >= 0; r-- {
+		label := ""
+		switch r {
+		case height - 1:
+			label = top
+		case 0:
+			label = bottom
+		}
+		var line string
+		for i := range cols {
+			line += pad(cols[i][r], colWidth)
+		}
+		fmt.Fprintf(&buf, " %*s │%s\n", labelWidth, label, strings.TrimRight(line, " "))
+	}
+	b := buf.Bytes()
+	return string(b[:len(b)-1]) // drop the \n
+}
+
+// writeAxis writes a horizontal axis for a plot width columns wide, after
+// an indent of labelWidth, labeled with lo and hi at its ends.
+func writeAxis(buf *bytes.Buffer, labelWidth int, lo, hi float64, u unit, width int) {
+	fmt.Fprintf(buf, " %*s └%s\n", labelWidth, "", strings.Repeat("─", width))
+	left, right := formatBound(lo, u), formatBound(hi, u)
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(buf, " %*s  %s%*s%s", labelWidth, "", left, gap, "", right)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/cespare/stats/summary"
+)
+
+func cdf(args []string) {
+	fs := flag.NewFlagSet("cdf", flag.ExitOnError)
+	points := fs.Int("points", 0, "If positive, print at most this many points, evenly spaced by cumulative fraction")
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *points < 0 {
+		log.Fatalf("-points must not be negative; got %d", *points)
+	}
+
+	nr, err := newNumberReader(inOpts, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	sr := summary.New()
+	for nr.Scan() {
+		sr.AddWeighted(nr.Value(), nr.Weight())
+	}
+	if err := nr.Err(); err != nil {
+		log.Fatal(err)
+	}
+	nr.warn()
+	if sr.Count() == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	ps := sr.ECDF()
+	if *points > 0 {
+		ps = downsampleCDF(ps, *points)
+	}
+	if err := writeRows(os.Stdout, format, cdfRows(ps, sr.Weight(), nr.Unit())); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// downsampleCDF reduces ps to at most n points: for each k in 1..n, the
+// first point whose cumulative weight reaches k/n of the total. Each
+// remaining point's weight becomes the total weight of the points it
+// replaces, so that the weights still add up.
+func downsampleCDF(ps []summary.CDFPoint, n int) []summary.CDFPoint {
+	if len(ps) <= n {
+		return ps
+	}
+	total := ps[len(ps)-1].Cumulative
+	var (
+		out  []summary.CDFPoint
+		prev float64 // cumulative weight of the last point kept
+		k    = 1
+	)
+	for i, p := range ps {
+		if p.Cumulative < float64(k)/float64(n)*total && i < len(ps)-1 {
+			continue
+		}
+		p.Weight = p.Cumulative - prev
+		prev = p.Cumulative
+		out = append(out, p)
+		for k <= n && float64(k)/float64(n)*total <= p.Cumulative {
+			k++
+		}
+	}
+	return out
+}
+
+func cdfRows(ps []summary.CDFPoint, total float64, u unit) [][]stat {
+	rows := make([][]stat, len(ps))
+	for i, p := range ps {
+		rows[i] = []stat{
+			{"value", "value", unitValue(p.Value, u)},
+			{"count", "count", weightValue(p.Weight)},
+			{"cumulative", "cumulative", weightValue(p.Cumulative)},
+			{"fraction", "fraction", p.Cumulative / total},
+		}
+	}
+	return rows
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/stats/summary"
+)
+
+// topValues is how many of the most common values describe lists for each
+// text column.
+const topValues = 3
+
+// A columnSummary accumulates the fields of one column of the input.
+type columnSummary struct {
+	sr    *summary.Summarizer
+	units *unitParser
+
+	// For describe.
+	count  int64            // non-empty fields
+	text   bool             // whether any field isn't a number
+	values map[string]int64 // occurrences of each distinct field
+}
+
+// summarizeColumns summarizes each field of the input separately. Fields
+// without any numbers are omitted. With -unit auto, each field may have a
+// different unit.
+//
+// If describe is set, text columns are described as well: the statistics
+// include, for every column, the number of non-empty fields and of distinct
+// ones, and for text columns, the most common values. A column is text if
+// any of its fields isn't a number.
+func summarizeColumns(inOpts *inputOptions, names []string, newSummarizer func() *summary.Summarizer, opts *summaryOptions, describe bool) {
+	colNames, rows, err := columnRows(inOpts, names, newSummarizer, opts, describe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(rows) == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	if opts.format == formatTable {
+		err = writeColumns(os.Stdout, colNames, rows)
+	} else {
+		err = writeRows(os.Stdout, opts.format, rows)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// columnRows reads the input and gives the name and the row of statistics
+// of each column for summarizeColumns.
+func columnRows(inOpts *inputOptions, names []string, newSummarizer func() *summary.Summarizer, opts *summaryOptions, describe bool) (colNames []string, rows [][]stat, err error) {
+	base, err := newUnitParser(inOpts.unit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := inOpts.check(); err != nil {
+		return nil, nil, err
+	}
+	rr := newRecordReader(inOpts, names)
+	var (
+		cols       []*columnSummary
+		nonNumeric int64
+	)
+	for rr.Scan() {
+		for i, s := range rr.Record() {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			for len(cols) <= i {
+				up := *base
+				c := &columnSummary{sr: newSummarizer(), units: &up}
+				if describe {
+					c.values = make(map[string]int64)
+				}
+				cols = append(cols, c)
+			}
+			c := cols[i]
+			if describe {
+				c.count++
+				c.values[s]++
+			}
+			v, err := c.units.parse(s)
+			if err != nil {
+				c.text = true
+				nonNumeric++
+				continue
+			}
+			c.sr.Add(v)
+		}
+	}
+	if err := rr.Err(); err != nil {
+		return nil, nil, err
+	}
+	if rr.noMatch > 0 {
+		log.Printf("warning: found %d lines that don't match -re", rr.noMatch)
+	}
+	if nonNumeric > 0 && !describe {
+		log.Printf("warning: found %d non-numeric fields", nonNumeric)
+	}
+
+	// For describe, text columns have the same statistics as numeric
+	// ones (including, with -approx, the rank errors), but with no values.
+	var emptyStats []stat
+	if describe {
+		emptyStats = opts.stats(newSummarizer())
+		for i := range emptyStats {
+			emptyStats[i].value = nil
+		}
+	}
+	for i, c := range cols {
+		if c.sr.Count() == 0 && !(describe && c.count > 0) {
+			continue
+		}
+		name := columnName(i, rr.header, inOpts.re)
+		colNames = append(colNames, name)
+		row := []stat{{"column", "column", name}}
+		opts.unit = c.units.unit
+		stats := opts.stats(c.sr)
+		if describe {
+			var top interface{}
+			if c.text {
+				stats = emptyStats
+				top = formatTopValues(c.values)
+			}
+			row = append(row,
+				stat{"count", "count", c.count},
+				stat{"distinct", "distinct", int64(len(c.values))},
+				stat{"top", "top values", top},
+			)
+			stats = withoutStat(stats, "count")
+		}
+		rows = append(rows, append(row, stats...))
+	}
+	return colNames, rows, nil
+}
+
+// columnName names the ith field (counting from 0) by the header, if there
+// is one, or by its capture group in re, if it is named, and otherwise by
+// its position. Without capture groups, re gives a single field, the whole
+// match.
+func columnName(i int, header []string, re *regexp.Regexp) string {
+	if i < len(header) {
+		return strings.TrimSpace(header[i])
+	}
+	if re != nil {
+		if names := re.SubexpNames(); i+1 < len(names) && names[i+1] != "" {
+			return names[i+1]
+		}
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// formatTopValues lists the most common values, most common first, with
+// their counts.
+func formatTopValues(counts map[string]int64) string {
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		ci, cj := counts[values[i]], counts[values[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > topValues {
+		values = values[:topValues]
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%s (%d)", v, counts[v])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func withoutStat(stats []stat, name string) []stat {
+	var out []stat
+	for _, st := range stats {
+		if st.name != name {
+			out = append(out, st)
+		}
+	}
+	return out
+}
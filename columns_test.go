@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/cespare/stats/summary"
+)
+
+func TestColumnName(t *testing.T) {
+	for _, tt := range []struct {
+		i      int
+		header []string
+		re     string
+		want   string
+	}{
+		{0, nil, "", "1"},
+		{1, []string{" a ", "b"}, "", "b"},
+		{2, []string{"a", "b"}, "", "3"},
+		{0, nil, `(?P<lat>\d+) (\d+)`, "lat"},
+		{1, nil, `(?P<lat>\d+) (\d+)`, "2"},
+		// Without capture groups, the whole match is the only field.
+		{0, nil, `[0-9]+`, "1"},
+	} {
+		var re *regexp.Regexp
+		if tt.re != "" {
+			re = regexp.MustCompile(tt.re)
+		}
+		if got := columnName(tt.i, tt.header, re); got != tt.want {
+			t.Errorf("columnName(%d, %q, %q): got %q; want %q", tt.i, tt.header, tt.re, got, tt.want)
+		}
+	}
+}
+
+func TestColumnRowsDescribeApprox(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "in.csv")
+	if err := os.WriteFile(name, []byte("lat,name\n1,a\n2,b\n3,c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("summarize", flag.ContinueOnError)
+	opts := addSummaryFlags(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.parse(&unitParser{}); err != nil {
+		t.Fatal(err)
+	}
+	inOpts := &inputOptions{csv: true, header: true, columns: true}
+	newSummarizer := func() *summary.Summarizer { return summary.NewApprox(100) }
+	colNames, rows, err := columnRows(inOpts, []string{name}, newSummarizer, opts, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"lat", "name"}; !reflect.DeepEqual(colNames, want) {
+		t.Fatalf("got columns %q; want %q", colNames, want)
+	}
+	// The text column has the same statistics as the numeric one,
+	// including the rank errors of the approximate quantiles.
+	statNames := func(row []stat) []string {
+		var names []string
+		for _, st := range row {
+			names = append(names, st.name)
+		}
+		return names
+	}
+	lat, text := statNames(rows[0]), statNames(rows[1])
+	if !reflect.DeepEqual(lat, text) {
+		t.Errorf("numeric column stats %q differ from text column stats %q", lat, text)
+	}
+	if !hasStat(rows[1], "p99_rank_error") {
+		t.Errorf("text column stats %q lack p99_rank_error", text)
+	}
+	if err := writeColumns(io.Discard, colNames, rows); err != nil {
+		t.Error(err)
+	}
+}
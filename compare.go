@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+
+	"github.com/cespare/stats/summary"
+	"github.com/cespare/tabular"
+)
+
+func compare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s compare [flags] A B\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Compare the numbers in two inputs (use - for stdin).\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	quantStr := fs.String("quantiles", "0.5,0.9,0.99", "Quantiles to compare")
+	methodStr := fs.String("quantile-method", "nearest", quantileMethodUsage)
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	quants, err := parseQuantiles(*quantStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	method, err := parseQuantileMethod(*methodStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		srs [2]*summary.Summarizer
+		u   unit
+	)
+	for i, name := range fs.Args() {
+		nr, err := newNumberReader(inOpts, []string{name})
+		if err != nil {
+			log.Fatal(err)
+		}
+		sr := summary.New()
+		sr.SetQuantileMethod(method)
+		for nr.Scan() {
+			sr.AddWeighted(nr.Value(), nr.Weight())
+		}
+		if err := nr.Err(); err != nil {
+			log.Fatal(err)
+		}
+		nr.warn()
+		if sr.Count() == 0 {
+			log.Fatalf("no numbers given in %s", name)
+		}
+		if nr.Unit() != unitNone {
+			u = nr.Unit()
+		}
+		srs[i] = sr
+	}
+
+	c := comparison{a: fs.Arg(0), b: fs.Arg(1)}
+	statsA := summaryStats(srs[0], quants, false, u)
+	statsB := summaryStats(srs[1], quants, false, u)
+	for i := range statsA {
+		c.rows = append(c.rows, comparisonRow{
+			stat: statsA[i],
+			a:    statFloat(statsA[i].value),
+			b:    statFloat(statsB[i].value),
+		})
+	}
+	c.welch, c.welchErr = summary.WelchTTest(srs[0], srs[1])
+	c.mwu, c.mwuErr = summary.MannWhitneyU(srs[0], srs[1])
+
+	if err := c.write(os.Stdout, format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A comparison holds side-by-side statistics for two inputs, a and b,
+// along with the results of significance tests.
+type comparison struct {
+	a, b     string // input names
+	rows     []comparisonRow
+	welch    summary.TestResult
+	welchErr error
+	mwu      summary.TestResult
+	mwuErr   error
+}
+
+type comparisonRow struct {
+	stat stat // the stat for a, for its name and label
+	a, b float64
+}
+
+func (r comparisonRow) delta() float64 { return r.b - r.a }
+
+// display gives v, a value of the row's stat, for the table format.
+func (r comparisonRow) display(v float64) interface{} {
+	if m, ok := r.stat.value.(measure); ok {
+		return measure{v, m.u}
+	}
+	return v
+}
+
+// formatDelta formats the delta, with its sign, for the table format.
+func (r comparisonRow) formatDelta() string {
+	m, ok := r.stat.value.(measure)
+	if !ok {
+		return fmt.Sprintf("%+g", r.delta())
+	}
+	s := m.u.format(r.delta())
+	if r.delta() >= 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+// deltaPct gives the change from a to b as a percentage of a.
+func (r comparisonRow) deltaPct() float64 {
+	if r.a == 0 {
+		return math.NaN()
+	}
+	return 100 * (r.b - r.a) / math.Abs(r.a)
+}
+
+func (c *comparison) write(w io.Writer, format outputFormat) error {
+	switch format {
+	case formatTable:
+		return c.writeTable(w)
+	case formatJSON:
+		return c.writeJSON(w)
+	case formatCSV:
+		return c.writeDelimited(w, ',')
+	case formatTSV:
+		return c.writeDelimited(w, '\t')
+	}
+	panic("unreached")
+}
+
+func (c *comparison) writeTable(w io.Writer) error {
+	tb := tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+	tb.AddRow("", tabular.Right(c.a), tabular.Right(c.b), tabular.Right("delta"), tabular.Right("delta %"))
+	for _, r := range c.rows {
+		pct := "n/a"
+		if d := r.deltaPct(); !math.IsNaN(d) {
+			pct = fmt.Sprintf("%+.2f%%", d)
+		}
+		tb.AddRow(
+			r.stat.label,
+			tabular.Right(r.display(r.a)),
+			tabular.Right(r.display(r.b)),
+			tabular.Right(r.formatDelta()),
+			tabular.Right(pct),
+		)
+	}
+	var buf bytes.Buffer
+	tb.WriteTo(&buf)
+	buf.WriteByte('\n')
+
+	tb = tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+	if c.welchErr != nil {
+		tb.AddRow("Welch's t-test", c.welchErr)
+	} else {
+		tb.AddRow("Welch's t-test", fmt.Sprintf("t = %.4g, df = %.4g, p = %.4g", c.welch.Statistic, c.welch.DF, c.welch.P))
+	}
+	if c.mwuErr != nil {
+		tb.AddRow("Mann-Whitney U test", c.mwuErr)
+	} else {
+		tb.AddRow("Mann-Whitney U test", fmt.Sprintf("U = %g, p = %.4g", c.mwu.Statistic, c.mwu.P))
+	}
+	tb.WriteTo(&buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (c *comparison) statRows() [][]stat {
+	rows := make([][]stat, len(c.rows))
+	for i, r := range c.rows {
+		rows[i] = []stat{
+			{"stat", "stat", r.stat.name},
+			{"a", c.a, r.a},
+			{"b", c.b, r.b},
+			{"delta", "delta", r.delta()},
+			{"delta_pct", "delta %", r.deltaPct()},
+		}
+	}
+	return rows
+}
+
+// testRows gives the results of the significance tests. Tests that could
+// not be performed have NaN results.
+func (c *comparison) testRows() [][]stat {
+	welch, mwu := c.welch, c.mwu
+	if c.welchErr != nil {
+		welch = summary.TestResult{Statistic: math.NaN(), DF: math.NaN(), P: math.NaN()}
+	}
+	if c.mwuErr != nil {
+		mwu = summary.TestResult{Statistic: math.NaN(), P: math.NaN()}
+	}
+	return [][]stat{
+		{
+			{"test", "test", "welch_t"},
+			{"statistic", "statistic", welch.Statistic},
+			{"df", "df", welch.DF},
+			{"p", "p", welch.P},
+		},
+		{
+			{"test", "test", "mann_whitney_u"},
+			{"statistic", "statistic", mwu.Statistic},
+			{"df", "df", math.NaN()},
+			{"p", "p", mwu.P},
+		},
+	}
+}
+
+// writeJSON writes a single object with the input names, an array of stats,
+// and an array of test results.
+func (c *comparison) writeJSON(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONFields(&buf, []stat{{"a", "a", c.a}, {"b", "b", c.b}})
+	for _, section := range []struct {
+		name string
+		rows [][]stat
+	}{
+		{"stats", c.statRows()},
+		{"tests", c.testRows()},
+	} {
+		fmt.Fprintf(&buf, `,%q:`, section.name)
+		writeJSONArray(&buf, section.rows)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeDelimited writes the stats followed, after a blank line, by the test
+// results.
+func (c *comparison) writeDelimited(w io.Writer, comma rune) error {
+	if err := writeDelimited(w, comma, c.statRows()); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return writeDelimited(w, comma, c.testRows())
+}
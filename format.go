@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/stats/summary"
+	"github.com/cespare/tabular"
+)
+
+// An outputFormat is a way of printing results.
+type outputFormat int
+
+const (
+	formatTable outputFormat = iota
+	formatJSON
+	formatCSV
+	formatTSV
+	// formatValues prints bare values for use in shell scripts. It is
+	// chosen with -value-only rather than -format.
+	formatValues
+)
+
+func parseFormat(s string) (outputFormat, error) {
+	switch s {
+	case "table":
+		return formatTable, nil
+	case "json":
+		return formatJSON, nil
+	case "csv":
+		return formatCSV, nil
+	case "tsv":
+		return formatTSV, nil
+	}
+	return 0, fmt.Errorf("unknown output format %q (must be table, json, csv, or tsv)", s)
+}
+
+// A stat is a single named statistic.
+type stat struct {
+	name  string      // stable name for machine-readable output
+	label string      // human-readable name for tabular output
+	value interface{} // int64, float64, measure, string, or nil (no value)
+}
+
+// summaryStats gives the statistics printed by summarize. If moments is set,
+// they include the higher moments and related statistics as well. The
+// statistics measured in the same terms as the values are given in the unit
+// u.
+func summaryStats(sr *summary.Summarizer, quants []float64, moments bool, u unit) []stat {
+	stats := []stat{
+		{"count", "count", weightValue(sr.Weight())},
+		{"min", "min", unitValue(sr.Min(), u)},
+		{"max", "max", unitValue(sr.Max(), u)},
+		{"mean", "mean", unitValue(sr.Mean(), u)},
+		{"stddev", "std. dev.", unitValue(sr.StdDev(), u)},
+	}
+	if moments {
+		stats = append(stats,
+			stat{"variance", "variance", sr.Variance()},
+			stat{"sample_variance", "sample variance", sr.SampleVariance()},
+			stat{"skewness", "skewness", sr.Skewness()},
+			stat{"kurtosis", "excess kurtosis", sr.Kurtosis()},
+			stat{"cv", "coeff. of variation", sr.CV()},
+			stat{"stderr", "std. error of mean", unitValue(sr.StdErr(), u)},
+		)
+	}
+	for i, v := range sr.Quantiles(quants) {
+		q := quants[i]
+		stats = append(stats, stat{quantileName(q), fmt.Sprintf("quantile %g", q), unitValue(v, u)})
+	}
+	if sr.Approx() {
+		// Report how far off each estimated quantile may be, as a
+		// fraction of the count.
+		for _, q := range quants {
+			name := quantileName(q) + "_rank_error"
+			label := fmt.Sprintf("quantile %g rank error", q)
+			stats = append(stats, stat{name, label, sr.RankError(q)})
+		}
+	}
+	return stats
+}
+
+// robustStats gives statistics that are little affected by outliers, and
+// the geometric and harmonic means, which are NaN unless every value is
+// positive. The trimmed mean leaves out the fraction trim of the values at
+// each end. Statistics measured in the same terms as the values are given in
+// the unit u.
+func robustStats(sr *summary.Summarizer, trim float64, u unit) []stat {
+	stats := []stat{
+		{"mad", "MAD", unitValue(sr.MAD(), u)},
+		{"mad_scaled", "scaled MAD", unitValue(sr.ScaledMAD(), u)},
+		{"iqr", "IQR", unitValue(sr.IQR(), u)},
+		{"trimmed_mean", fmt.Sprintf("%g%% trimmed mean", trim*100), unitValue(sr.TrimmedMean(trim), u)},
+		{"geometric_mean", "geometric mean", unitValue(sr.GeometricMean(), u)},
+		{"harmonic_mean", "harmonic mean", unitValue(sr.HarmonicMean(), u)},
+	}
+	// The mode is the smallest of the tied modes, and modes is the number
+	// of them. There is no mode if every distinct value ties.
+	modes, weight := sr.Modes()
+	var mode, numModes, modeWeight interface{} // nil for an approximate Summarizer
+	switch {
+	case modes == nil:
+	case len(modes) > 1 && allTie(float64(len(modes))*weight, sr.Weight()):
+		numModes = int64(0)
+	default:
+		mode = unitValue(modes[0], u)
+		numModes = int64(len(modes))
+		modeWeight = weightValue(weight)
+	}
+	return append(stats,
+		stat{"mode", "mode", mode},
+		stat{"modes", "number of modes", numModes},
+		stat{"mode_count", "mode count", modeWeight},
+	)
+}
+
+// allTie reports whether the tied modes, with a total weight of modeWeight,
+// make up all of the total weight, allowing for rounding of fractional
+// weights.
+func allTie(modeWeight, total float64) bool {
+	return math.Abs(modeWeight-total) <= 1e-9*total
+}
+
+// thresholdStats gives the total weight (the count, for unweighted input)
+// and the fraction of the values at or below each threshold in below and
+// above each threshold in above. The thresholds are labeled in the unit u.
+func thresholdStats(sr *summary.Summarizer, below, above []float64, u unit) []stat {
+	var stats []stat
+	total := sr.Weight()
+	for _, x := range below {
+		n := sr.Rank(x)
+		name := "below_" + formatValue(x)
+		label := "≤ " + u.format(x)
+		stats = append(stats,
+			stat{name, "count " + label, weightValue(n)},
+			stat{name + "_fraction", "fraction " + label, n / total},
+		)
+	}
+	for _, x := range above {
+		n := total - sr.Rank(x)
+		name := "above_" + formatValue(x)
+		label := "> " + u.format(x)
+		stats = append(stats,
+			stat{name, "count " + label, weightValue(n)},
+			stat{name + "_fraction", "fraction " + label, n / total},
+		)
+	}
+	return stats
+}
+
+// ciStats gives confidence intervals at the given level for the mean, the
+// median, and each quantile in quants: bootstrap intervals (percentile and
+// BCa) using iters resamples drawn with the given seed, and for quantiles,
+// the interval formed by order statistics. Intervals that can't be computed
+// for lack of values are NaN. The bounds are given in the unit u.
+func ciStats(sr *summary.Summarizer, quants []float64, level float64, iters int, seed int64, u unit) []stat {
+	qs := quants
+	hasMedian := false
+	for _, q := range quants {
+		hasMedian = hasMedian || q == 0.5
+	}
+	if !hasMedian {
+		qs = append([]float64{0.5}, quants...)
+	}
+	nan := summary.Interval{Lo: math.NaN(), Hi: math.NaN()}
+	res, err := sr.Bootstrap(qs, level, iters, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		res.Mean = summary.BootstrapInterval{Percentile: nan, BCa: nan}
+		res.Quantiles = make([]summary.BootstrapInterval, len(qs))
+		for i := range res.Quantiles {
+			res.Quantiles[i] = res.Mean
+		}
+	}
+
+	var stats []stat
+	pct := strconv.FormatFloat(level*100, 'f', -1, 64) + "%"
+	add := func(name, label string, iv summary.Interval) {
+		stats = append(stats,
+			stat{name + "_lo", label + " low", unitValue(iv.Lo, u)},
+			stat{name + "_hi", label + " high", unitValue(iv.Hi, u)},
+		)
+	}
+	add("mean_ci", "mean "+pct+" CI", res.Mean.Percentile)
+	add("mean_bca", "mean "+pct+" BCa CI", res.Mean.BCa)
+	for i, q := range qs {
+		name, label := quantileName(q), fmt.Sprintf("quantile %g", q)
+		add(name+"_ci", label+" "+pct+" CI", res.Quantiles[i].Percentile)
+		add(name+"_bca", label+" "+pct+" BCa CI", res.Quantiles[i].BCa)
+		iv, err := sr.QuantileCI(q, level)
+		if err != nil {
+			iv = nan
+		}
+		add(name+"_os", label+" "+pct+" order stat. CI", iv)
+	}
+	return stats
+}
+
+// outlierStats gives the fences outside of which method m with threshold k
+// flags values as outliers, in the unit u, and the total weight (the count,
+// for unweighted input) of the outliers on each side.
+func outlierStats(sr *summary.Summarizer, m summary.OutlierMethod, k float64, u unit) []stat {
+	lo, hi := sr.OutlierFences(m, k)
+	below := sr.Rank(math.Nextafter(lo, math.Inf(-1)))
+	above := sr.Weight() - sr.Rank(hi)
+	return []stat{
+		{"outlier_fence_lo", "outlier fence low", unitValue(lo, u)},
+		{"outlier_fence_hi", "outlier fence high", unitValue(hi, u)},
+		{"outliers_lo", "outliers below", weightValue(below)},
+		{"outliers_hi", "outliers above", weightValue(above)},
+		{"outliers_fraction", "outlier fraction", (below + above) / sr.Weight()},
+	}
+}
+
+// weightValue gives a total weight (which, for unweighted input, is a count)
+// as an int64 if it is a whole number so that it prints as one.
+func weightValue(w float64) interface{} {
+	if w == math.Trunc(w) && math.Abs(w) < 1<<53 {
+		return int64(w)
+	}
+	return w
+}
+
+// quantileName gives the percentile name for q: p50, p99.9, and so on.
+func quantileName(q float64) string {
+	p := math.Round(q*100*1e9) / 1e9 // avoid printing 99.89999999999999
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// writeSummary writes stats and, if h is non-nil, the histogram h to w. The
+// histogram's bucket labels are given in the unit u.
+func writeSummary(w io.Writer, format outputFormat, stats []stat, h *summary.Histogram, u unit) error {
+	switch format {
+	case formatTable:
+		if _, err := fmt.Fprintln(w, formatStats(stats)); err != nil {
+			return err
+		}
+		if h != nil {
+			if _, err := fmt.Fprintln(w, formatHist(h, u)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		return writeSummaryJSON(w, stats, h)
+	case formatCSV:
+		return writeSummaryDelimited(w, ',', stats, h)
+	case formatTSV:
+		return writeSummaryDelimited(w, '\t', stats, h)
+	case formatValues:
+		for _, st := range stats {
+			if _, err := fmt.Fprintln(w, formatValue(st.value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	panic("unreached")
+}
+
+// writeRows writes several rows of stats, each of which has the same names.
+// The table format has a header of stat names and one line per row; the JSON
+// format has one object per line.
+func writeRows(w io.Writer, format outputFormat, rows [][]stat) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	switch format {
+	case formatTable:
+		tb := tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+		header := make([]interface{}, len(rows[0]))
+		for i, st := range rows[0] {
+			header[i] = alignCell(st.value, st.name)
+		}
+		tb.AddRow(header...)
+		for _, row := range rows {
+			cells := make([]interface{}, len(row))
+			for i, st := range row {
+				cells[i] = alignCell(st.value, tableCell(st.value))
+			}
+			tb.AddRow(cells...)
+		}
+		_, err := tb.WriteTo(w)
+		return err
+	case formatJSON:
+		for _, row := range rows {
+			if err := writeSummaryJSON(w, row, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatCSV:
+		return writeDelimited(w, ',', rows)
+	case formatTSV:
+		return writeDelimited(w, '\t', rows)
+	case formatValues:
+		for _, row := range rows {
+			values := make([]string, len(row))
+			for i, st := range row {
+				values[i] = formatValue(st.value)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(values, " ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	panic("unreached")
+}
+
+// writeColumns writes a table with a column of stats for each of several
+// inputs, headed by their names. Each row holds one stat, labeled in the
+// first column; stats without values are left blank. The first stat of each
+// column (its name) is skipped.
+func writeColumns(w io.Writer, names []string, cols [][]stat) error {
+	tb := tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+	header := []interface{}{""}
+	for _, name := range names {
+		header = append(header, tabular.Right(name))
+	}
+	tb.AddRow(header...)
+	for i := 1; i < len(cols[0]); i++ {
+		row := []interface{}{cols[0][i].label}
+		for _, col := range cols {
+			row = append(row, tabular.Right(tableCell(col[i].value)))
+		}
+		tb.AddRow(row...)
+	}
+	_, err := tb.WriteTo(w)
+	return err
+}
+
+// tableCell gives a stat value as a table cell, which is empty if there is
+// no value.
+func tableCell(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// alignCell right-aligns numeric columns.
+func alignCell(v, cell interface{}) interface{} {
+	if _, ok := v.(string); ok {
+		return cell
+	}
+	return tabular.Right(cell)
+}
+
+func formatStats(stats []stat) string {
+	tb := tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+	for _, st := range stats {
+		tb.AddRow(st.label, tableCell(st.value))
+	}
+	var buf bytes.Buffer
+	tb.WriteTo(&buf)
+	b := buf.Bytes()
+	return string(b[:len(b)-1]) // drop the \n
+}
+
+// writeSummaryJSON writes a single JSON object containing each stat by name
+// and, if h is non-nil, a "histogram" array of buckets.
+func writeSummaryJSON(w io.Writer, stats []stat, h *summary.Histogram) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONFields(&buf, stats)
+	if h != nil {
+		buf.WriteString(`,"histogram":`)
+		writeJSONArray(&buf, histStats(h))
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSONFields writes the stats as the comma-separated fields of a JSON
+// object (without the surrounding braces).
+func writeJSONFields(buf *bytes.Buffer, stats []stat) {
+	for i, st := range stats {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONField(buf, st.name, st.value)
+	}
+}
+
+// writeJSONArray writes the rows as a JSON array of objects.
+func writeJSONArray(buf *bytes.Buffer, rows [][]stat) {
+	buf.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		writeJSONFields(buf, row)
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+}
+
+func writeJSONField(buf *bytes.Buffer, name string, v interface{}) {
+	k, err := json.Marshal(name)
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(k)
+	buf.WriteByte(':')
+	if m, ok := v.(measure); ok {
+		v = m.v
+	}
+	// JSON cannot represent NaN or infinities.
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		buf.WriteString("null")
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+}
+
+// writeSummaryDelimited writes the stats as a header row of names followed
+// by a row of values. If h is non-nil, the histogram follows after a blank
+// line as a second table with one row per bucket.
+func writeSummaryDelimited(w io.Writer, comma rune, stats []stat, h *summary.Histogram) error {
+	if err := writeDelimited(w, comma, [][]stat{stats}); err != nil {
+		return err
+	}
+	if h != nil {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+		return writeDelimited(w, comma, histStats(h))
+	}
+	return nil
+}
+
+// writeDelimited writes a header row of stat names followed by the values
+// of each row.
+func writeDelimited(w io.Writer, comma rune, rows [][]stat) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	names := make([]string, len(rows[0]))
+	for i, st := range rows[0] {
+		names[i] = st.name
+	}
+	cw.Write(names)
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return writeDelimitedValues(w, comma, rows)
+}
+
+// writeDelimitedValues is like writeDelimited but omits the header row.
+func writeDelimitedValues(w io.Writer, comma rune, rows [][]stat) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	for _, row := range rows {
+		values := make([]string, len(row))
+		for i, st := range row {
+			values[i] = formatValue(st.value)
+		}
+		cw.Write(values)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case measure:
+		return strconv.FormatFloat(v.v, 'g', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cespare/stats/summary"
+)
+
+func TestRobustStatsMode(t *testing.T) {
+	for _, tt := range []struct {
+		vs   []float64
+		want []interface{} // mode, modes, mode_count
+	}{
+		{[]float64{1, 2, 2, 3}, []interface{}{2.0, int64(1), int64(2)}},
+		{[]float64{1, 3, 3, 2, 2}, []interface{}{2.0, int64(2), int64(2)}},
+		{[]float64{5, 5}, []interface{}{5.0, int64(1), int64(2)}},
+		{[]float64{1, 2, 3}, []interface{}{nil, int64(0), nil}},
+	} {
+		sr := summary.New()
+		for _, v := range tt.vs {
+			sr.Add(v)
+		}
+		var got []interface{}
+		for _, st := range robustStats(sr, 0.1, unitNone) {
+			switch st.name {
+			case "mode", "modes", "mode_count":
+				got = append(got, st.value)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mode stats of %v: got %v; want %v", tt.vs, got, tt.want)
+		}
+	}
+}
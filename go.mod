@@ -3,7 +3,6 @@ module github.com/cespare/stats
 go 1.17
 
 require (
-	github.com/cespare/argf v0.1.0
 	github.com/cespare/subcmd v1.1.0
 	github.com/cespare/tabular v0.0.1
 )
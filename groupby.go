@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cespare/stats/summary"
+)
+
+// groupRows computes a row of stats for each group using statsFn, prefixed by
+// the group key. The rows are ordered by key or, if sortBy is not empty, by
+// the named stat in descending order. If top is positive, only the first top
+// rows are returned.
+func groupRows(groups map[string]*summary.Summarizer, statsFn func(*summary.Summarizer) []stat, sortBy string, top int) ([][]stat, error) {
+	rows := make([][]stat, 0, len(groups))
+	for key, sr := range groups {
+		row := append([]stat{{"group", "group", key}}, statsFn(sr)...)
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0].value.(string) < rows[j][0].value.(string)
+	})
+	if sortBy != "" && sortBy != "group" && len(rows) > 0 {
+		col := -1
+		var names []string
+		for i, st := range rows[0] {
+			if st.name == sortBy {
+				col = i
+			}
+			names = append(names, st.name)
+		}
+		if col < 0 {
+			return nil, fmt.Errorf("cannot sort by %q (must be one of %s)", sortBy, strings.Join(names, ", "))
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			vi := statFloat(rows[i][col].value)
+			vj := statFloat(rows[j][col].value)
+			if math.IsNaN(vj) {
+				return !math.IsNaN(vi)
+			}
+			return vi > vj
+		})
+	}
+	if top > 0 && len(rows) > top {
+		rows = rows[:top]
+	}
+	return rows, nil
+}
+
+func statFloat(v interface{}) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case measure:
+		return v.v
+	}
+	return math.NaN()
+}
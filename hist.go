@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cespare/stats/summary"
+)
+
+func hist(args []string) {
+	fs := flag.NewFlagSet("hist", flag.ExitOnError)
+	histOpts := addHistFlags(fs)
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	units, err := newUnitParser(inOpts.unit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := histOpts.parse(units); err != nil {
+		log.Fatal(err)
+	}
+
+	nr, err := newNumberReader(inOpts, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	sr := summary.New()
+	for nr.Scan() {
+		sr.AddWeighted(nr.Value(), nr.Weight())
+	}
+	if err := nr.Err(); err != nil {
+		log.Fatal(err)
+	}
+	nr.warn()
+	if sr.Count() == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	h, err := histOpts.histogram(sr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeHist(os.Stdout, format, h, nr.Unit()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// histOptions are the flags that control the layout of a histogram.
+type histOptions struct {
+	buckets   int
+	log       bool
+	nice      bool
+	boundsStr string
+	minStr    string
+	maxStr    string
+	fs        *flag.FlagSet
+
+	// Set by parse.
+	bounds []float64
+	min    float64
+	max    float64
+	hasMin bool
+	hasMax bool
+}
+
+func addHistFlags(fs *flag.FlagSet) *histOptions {
+	o := histOptions{fs: fs}
+	fs.IntVar(&o.buckets, "buckets", 10, "How many buckets for the histogram")
+	fs.BoolVar(&o.log, "log", false, "Use logarithmic histogram bucket widths")
+	fs.BoolVar(&o.nice, "nice", false, "Round histogram bucket boundaries to nice numbers")
+	fs.StringVar(&o.boundsStr, "bounds", "", "Comma-separated histogram bucket boundaries")
+	fs.StringVar(&o.minStr, "min", "", "Start the histogram at this value, counting smaller values separately")
+	fs.StringVar(&o.maxStr, "max", "", "End the histogram at this value, counting larger values separately")
+	return &o
+}
+
+// parse validates the flags after they have been parsed. Bounds are parsed
+// in the unit of the input, as given by units.
+func (o *histOptions) parse(units *unitParser) error {
+	if o.buckets <= 1 {
+		return fmt.Errorf("%d is an invalid number of buckets", o.buckets)
+	}
+	if o.boundsStr != "" {
+		conflict := false
+		o.fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "min", "max", "buckets", "log":
+				conflict = true
+			}
+		})
+		if conflict {
+			return errors.New("-bounds cannot be used with -min, -max, -buckets, or -log")
+		}
+	}
+	var err error
+	if o.minStr != "" {
+		if o.min, err = parseValue(o.minStr, units); err != nil {
+			return err
+		}
+		o.hasMin = true
+	}
+	if o.maxStr != "" {
+		if o.max, err = parseValue(o.maxStr, units); err != nil {
+			return err
+		}
+		o.hasMax = true
+	}
+	if o.hasMin && o.hasMax && o.min >= o.max {
+		return fmt.Errorf("histogram -min (%g) must be less than -max (%g)", o.min, o.max)
+	}
+	if o.boundsStr == "" {
+		return nil
+	}
+	if o.bounds, err = parseFloats(o.boundsStr, units); err != nil {
+		return err
+	}
+	if len(o.bounds) < 2 {
+		return fmt.Errorf("need at least two histogram bounds; got %d", len(o.bounds))
+	}
+	if !sort.Float64sAreSorted(o.bounds) {
+		return fmt.Errorf("histogram bounds must be in increasing order")
+	}
+	return nil
+}
+
+func (o *histOptions) histogram(sr *summary.Summarizer) (*summary.Histogram, error) {
+	if o.bounds != nil {
+		return sr.HistogramBounds(o.bounds), nil
+	}
+	lo, hi := sr.Min(), sr.Max()
+	if o.hasMin {
+		lo = o.min
+	}
+	if o.hasMax {
+		hi = o.max
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("histogram range [%g, %g] is empty", lo, hi)
+	}
+	var (
+		bounds []float64
+		err    error
+	)
+	switch {
+	case o.log && o.nice:
+		bounds, err = summary.NiceLogBounds(lo, hi)
+	case o.log:
+		bounds, err = summary.LogBounds(lo, hi, o.buckets)
+	case o.nice:
+		bounds = summary.NiceBounds(lo, hi, o.buckets)
+	default:
+		bounds = summary.LinearBounds(lo, hi, o.buckets)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot use logarithmic buckets for the range [%g, %g] (use -min to set a positive lower bound)", lo, hi)
+	}
+	return sr.HistogramBounds(bounds), nil
+}
+
+// writeHist writes just a histogram, labeling the buckets in the unit u.
+func writeHist(w io.Writer, format outputFormat, h *summary.Histogram, u unit) error {
+	switch format {
+	case formatTable:
+		_, err := fmt.Fprintln(w, formatHist(h, u))
+		return err
+	case formatJSON:
+		var buf bytes.Buffer
+		buf.WriteString(`{"histogram":`)
+		writeJSONArray(&buf, histStats(h))
+		buf.WriteString("}\n")
+		_, err := w.Write(buf.Bytes())
+		return err
+	case formatCSV:
+		return writeDelimited(w, ',', histStats(h))
+	case formatTSV:
+		return writeDelimited(w, '\t', histStats(h))
+	}
+	panic("unreached")
+}
+
+// A histRow is a histogram bucket for display. The underflow and overflow
+// buckets, if not empty, have infinite starts and ends, respectively.
+type histRow struct {
+	start float64
+	end   float64
+	count float64
+}
+
+func histRows(h *summary.Histogram) []histRow {
+	var rows []histRow
+	if h.Underflow > 0 {
+		rows = append(rows, histRow{math.Inf(-1), h.Buckets[0].Start, h.Underflow})
+	}
+	for _, b := range h.Buckets {
+		rows = append(rows, histRow{b.Start, b.End, b.Count})
+	}
+	if h.Overflow > 0 {
+		rows = append(rows, histRow{h.Buckets[len(h.Buckets)-1].End, math.Inf(1), h.Overflow})
+	}
+	return rows
+}
+
+// histStats gives the histogram buckets as rows of stats for machine-readable
+// output.
+func histStats(h *summary.Histogram) [][]stat {
+	rows := histRows(h)
+	var total float64
+	for _, r := range rows {
+		total += r.count
+	}
+	stats := make([][]stat, len(rows))
+	for i, r := range rows {
+		stats[i] = []stat{
+			{"start", "start", r.start},
+			{"end", "end", r.end},
+			{"count", "count", weightValue(r.count)},
+			{"fraction", "fraction", r.count / total},
+		}
+	}
+	return stats
+}
+
+const histBlocks = 70
+
+func formatHist(h *summary.Histogram, u unit) string {
+	rows := histRows(h)
+	labels := make([]string, len(rows))
+	labelSpaceBefore := 0
+	labelSpaceAfter := 0
+	last := len(rows) - 1 // the last bucket, which includes its end
+	if h.Overflow > 0 {
+		last--
+	}
+	var maxCount, sum float64
+	for i, r := range rows {
+		sum += r.count
+		start, end := formatBound(r.start, u), formatBound(r.end, u)
+		var label string
+		switch {
+		case math.IsInf(r.start, -1):
+			label = fmt.Sprintf("x < %s", end)
+		case math.IsInf(r.end, 1):
+			label = fmt.Sprintf("%s < x", start)
+		case i == last:
+			label = fmt.Sprintf("%s ≤ x ≤ %s", start, end)
+		default:
+			label = fmt.Sprintf("%s ≤ x < %s", start, end)
+		}
+		xPos := runeIndex(label, 'x')
+		if xPos > labelSpaceBefore {
+			labelSpaceBefore = xPos
+		}
+		if after := utf8.RuneCountInString(label) - xPos - 1; after > labelSpaceAfter {
+			labelSpaceAfter = after
+		}
+		labels[i] = label
+		if r.count > maxCount {
+			maxCount = r.count
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, r := range rows {
+		xPos := runeIndex(labels[i], 'x')
+		before := labelSpaceBefore - xPos
+		after := labelSpaceAfter - utf8.RuneCountInString(labels[i]) + xPos + 1
+		fmt.Fprintf(&buf, " %*s%s%*s │", before, "", labels[i], after, "")
+		fmt.Fprint(&buf, bar((r.count/maxCount)*histBlocks))
+		fmt.Fprintf(&buf, " %s (%.3f%%)\n", formatValue(weightValue(r.count)), 100*r.count/sum)
+	}
+	b := buf.Bytes()
+	return string(b[:len(b)-1]) // drop the \n
+}
+
+// formatBound formats a bucket boundary for a histogram label.
+func formatBound(v float64, u unit) string {
+	if u == unitNone {
+		return fmt.Sprintf("%.3g", v)
+	}
+	return u.format(v)
+}
+
+func runeIndex(s string, r rune) int {
+	for i, r2 := range []rune(s) {
+		if r2 == r {
+			return i
+		}
+	}
+	return -1
+}
+
+var barEighths = [9]rune{
+	' ', // empty
+	'▏',
+	'▎',
+	'▍',
+	'▌',
+	'▋',
+	'▊',
+	'▉',
+	'█', // full
+}
+
+func bar(n float64) string {
+	eighths := int(round(n * 8))
+	full := eighths / 8
+	rem := eighths % 8
+	return strings.Repeat(string(barEighths[8]), full) + string(barEighths[rem])
+}
+
+// assumes positive v
+func round(v float64) int64 {
+	return int64(v + 0.5)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestHistOptionsBoundsConflict(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		ok   bool
+	}{
+		{[]string{"-bounds", "1,5,10"}, true},
+		{[]string{"-bounds", "1,5,10", "-nice"}, true},
+		{[]string{"-buckets", "5", "-min", "0", "-log"}, true},
+		{[]string{"-bounds", "1,5,10", "-min", "0"}, false},
+		{[]string{"-bounds", "1,5,10", "-max", "20"}, false},
+		{[]string{"-bounds", "1,5,10", "-buckets", "10"}, false},
+		{[]string{"-bounds", "1,5,10", "-log"}, false},
+	} {
+		fs := flag.NewFlagSet("hist", flag.ContinueOnError)
+		o := addHistFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		err := o.parse(&unitParser{})
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("%q: got err %v; want ok = %t", tt.args, err, tt.ok)
+		}
+	}
+}
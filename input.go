@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// inputOptions control how lines of input are split into fields.
+type inputOptions struct {
+	field  string
+	delim  string
+	csv    bool
+	header bool
+
+	weighted    bool
+	weightField string
+	unit        string
+	tokens      bool
+
+	// re, if set, extracts fields from each line: the fields are its
+	// capture groups, which may also be selected by name.
+	re *regexp.Regexp
+
+	// key, if set, selects a field to group records by. It is not
+	// registered by addInputFlags; commands that support grouping add
+	// their own flag for it.
+	key string
+
+	// columns, set by summarize -columns and by regress, means that lines
+	// are always split into fields because several of them are read.
+	columns bool
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputOptions {
+	o := addRecordFlags(fs)
+	fs.StringVar(&o.field, "field", "", "Read numbers from this field (a 1-based index or, with -header, a name) instead of the whole line")
+	fs.BoolVar(&o.weighted, "weighted", false, "Weight each number by the value of another field (see -weight-field)")
+	fs.StringVar(&o.weightField, "weight-field", "2", "With -weighted, read weights from this field")
+	fs.BoolVar(&o.tokens, "tokens", false, "Read every number on each line, split on whitespace or -delim")
+	return o
+}
+
+// addRecordFlags adds the input flags that control how lines are split into
+// fields, for commands that choose which fields to read themselves.
+func addRecordFlags(fs *flag.FlagSet) *inputOptions {
+	var o inputOptions
+	fs.StringVar(&o.delim, "delim", "", "Field delimiter (default: runs of whitespace, or a comma with -csv)")
+	fs.BoolVar(&o.csv, "csv", false, "Parse input as CSV, respecting quoted fields")
+	fs.BoolVar(&o.header, "header", false, "Treat the first line of each input as a header naming the fields")
+	fs.StringVar(&o.unit, "unit", "", unitUsage)
+	fs.Func("re", "Read fields from the capture groups of this regular expression, skipping lines that don't match "+
+		"(numbers come from the group named \"value\" or else the first)", func(s string) error {
+		re, err := regexp.Compile(s)
+		o.re = re
+		return err
+	})
+	return &o
+}
+
+// split reports whether lines are divided into fields at all. If not, each
+// record is a single field holding the whole line.
+func (o *inputOptions) split() bool {
+	return o.field != "" || o.delim != "" || o.csv || o.header || o.key != "" || o.weighted || o.re != nil ||
+		o.tokens || o.columns
+}
+
+// check reports incompatible input options.
+func (o *inputOptions) check() error {
+	if o.re != nil && (o.csv || o.delim != "" || o.header) {
+		return errors.New("-re cannot be used with -csv, -delim, or -header")
+	}
+	return nil
+}
+
+// hasGroup reports whether -re has a capture group with the given name.
+func (o *inputOptions) hasGroup(name string) bool {
+	return o.re != nil && o.re.SubexpIndex(name) >= 0
+}
+
+// A recordReader reads records (lines split into fields) from a list of files
+// or, if there are none, from stdin. A file named "-" also means stdin. The
+// interface resembles bufio.Scanner.
+type recordReader struct {
+	opts  *inputOptions
+	names []string
+	cols  []*column // resolved against each header
+	stdin io.Reader // read in place of os.Stdin, if set before the first Scan
+
+	name string // current input name
+	f    *os.File
+	br   *bufio.Reader // in non-CSV mode
+	cr   *csv.Reader   // in CSV mode
+	line int           // line number of the current record
+	text string        // text of the current line, in non-CSV mode
+	rec  []string
+
+	needHeader bool     // whether the next record is a header
+	header     []string // the most recent header
+	done       bool
+	err        error
+
+	noMatch int64 // lines that don't match -re
+}
+
+func newRecordReader(opts *inputOptions, names []string) *recordReader {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	return &recordReader{opts: opts, names: names}
+}
+
+func (r *recordReader) setInput(rd io.Reader) {
+	r.line = 0
+	r.needHeader = r.opts.header
+	if r.opts.csv {
+		r.cr = csv.NewReader(rd)
+		r.cr.FieldsPerRecord = -1
+		r.cr.ReuseRecord = true
+		if r.opts.delim != "" {
+			c, size := utf8.DecodeRuneInString(r.opts.delim)
+			if size != len(r.opts.delim) {
+				r.err = fmt.Errorf("CSV delimiter must be a single character; got %q", r.opts.delim)
+				return
+			}
+			r.cr.Comma = c
+		}
+		return
+	}
+	r.br = bufio.NewReader(rd)
+}
+
+// Scan advances to the next record, which is then available through Record.
+// It returns false when there is no more input or if there is an error, in
+// which case Err returns the error.
+func (r *recordReader) Scan() bool {
+	for !r.done && r.err == nil {
+		if r.br == nil && r.cr == nil {
+			if !r.nextFile() {
+				return false
+			}
+			continue
+		}
+		if !r.read() {
+			continue
+		}
+		if r.needHeader {
+			r.needHeader = false
+			r.header = append(r.header[:0], r.rec...)
+			for _, c := range r.cols {
+				if err := c.resolve(r.rec); err != nil {
+					r.err = fmt.Errorf("%s: %s", r.name, err)
+					return false
+				}
+			}
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (r *recordReader) nextFile() bool {
+	if r.f != nil {
+		r.f.Close()
+		r.f = nil
+	}
+	if len(r.names) == 0 {
+		r.done = true
+		return false
+	}
+	r.name = r.names[0]
+	r.names = r.names[1:]
+	if r.name == "-" {
+		r.name = "<stdin>"
+		if r.stdin != nil {
+			r.setInput(r.stdin)
+		} else {
+			r.setInput(os.Stdin)
+		}
+		return r.err == nil
+	}
+	f, err := os.Open(r.name)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.f = f
+	r.setInput(f)
+	return r.err == nil
+}
+
+// read reads a single record from the current input. It returns false at
+// the end of the input (after arranging for the next input to be opened) or
+// if the line is blank.
+func (r *recordReader) read() bool {
+	if r.cr != nil {
+		rec, err := r.cr.Read()
+		if err == io.EOF {
+			r.endInput()
+			return false
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				r.err = fmt.Errorf("%s:%d: %s", r.name, perr.Line, perr.Err)
+			} else {
+				r.err = err
+			}
+			return false
+		}
+		r.line, _ = r.cr.FieldPos(0)
+		r.rec = rec
+		return true
+	}
+
+	line, err := r.br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		r.err = err
+		return false
+	}
+	if err == io.EOF && line == "" {
+		r.endInput()
+		return false
+	}
+	r.line++
+	r.text = strings.TrimRight(line, "\r\n")
+	if r.text == "" {
+		return false
+	}
+	switch {
+	case r.opts.re != nil:
+		m := r.opts.re.FindStringSubmatch(r.text)
+		if m == nil {
+			r.noMatch++
+			return false
+		}
+		if len(m) > 1 {
+			m = m[1:] // just the groups
+		}
+		r.rec = append(r.rec[:0], m...)
+	case !r.opts.split():
+		r.rec = append(r.rec[:0], r.text)
+	case r.opts.delim == "":
+		r.rec = strings.Fields(r.text)
+	default:
+		r.rec = strings.Split(r.text, r.opts.delim)
+	}
+	return true
+}
+
+func (r *recordReader) endInput() {
+	r.br = nil
+	r.cr = nil
+	if r.f == nil && len(r.names) == 0 {
+		r.done = true
+	}
+}
+
+// Record returns the fields of the current record. The slice may be reused
+// by the next call to Scan.
+func (r *recordReader) Record() []string { return r.rec }
+
+// Err returns the first non-EOF error encountered by Scan.
+func (r *recordReader) Err() error { return r.err }
+
+// column parses a column specification and arranges for it to be resolved
+// against the header of each input, if there is one. With -re, the column is
+// a capture group, and it is resolved immediately.
+func (r *recordReader) column(spec string) (*column, error) {
+	re := r.opts.re
+	c, err := parseColumn(spec, r.opts.header || re != nil)
+	if err != nil {
+		return nil, err
+	}
+	if re != nil {
+		if err := c.resolve(re.SubexpNames()[1:]); err != nil {
+			return nil, fmt.Errorf("-re has no capture group named %q", spec)
+		}
+		if n := re.NumSubexp(); n > 0 && c.index >= n {
+			return nil, fmt.Errorf("-re has no capture group %s (it has %d)", spec, n)
+		}
+		return c, nil
+	}
+	r.cols = append(r.cols, c)
+	return c, nil
+}
+
+// A column identifies a field of each record, either by its 1-based position
+// or, if the input has a header, by name.
+type column struct {
+	spec  string
+	index int
+}
+
+func parseColumn(spec string, header bool) (*column, error) {
+	c := &column{spec: spec, index: -1}
+	n, err := strconv.Atoi(spec)
+	if err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("invalid field %d (fields are numbered starting at 1)", n)
+		}
+		c.index = n - 1
+		return c, nil
+	}
+	if !header {
+		return nil, fmt.Errorf("field %q is not a number (use -header to select fields by name)", spec)
+	}
+	return c, nil
+}
+
+// resolve finds the column in a header row. A column given by position is
+// only matched by name if the header contains that name.
+func (c *column) resolve(header []string) error {
+	for i, name := range header {
+		if strings.TrimSpace(name) == c.spec {
+			c.index = i
+			return nil
+		}
+	}
+	if _, err := strconv.Atoi(c.spec); err == nil {
+		return nil
+	}
+	return fmt.Errorf("no field named %q in header", c.spec)
+}
+
+func (c *column) get(rec []string) (string, bool) {
+	if c.index < 0 || c.index >= len(rec) {
+		return "", false
+	}
+	return rec[c.index], true
+}
+
+// A numberReader reads one number from each record of its input, skipping
+// and counting the records for which that isn't possible. If the input
+// options include a key field, each number is accompanied by a key. If the
+// input is weighted, each number is also accompanied by a weight.
+type numberReader struct {
+	*recordReader
+	col       *column // nil when reading whole lines
+	keyCol    *column // nil if not grouping
+	weightCol *column // nil if unweighted
+	units     *unitParser
+	v         float64
+	key       string
+	weight    float64
+
+	nonNumeric int64 // non-numeric lines (reading whole lines)
+	missing    int64 // records without the selected field
+	badField   int64 // records where the selected field is not numeric
+	missingKey int64 // records without the key field
+	badWeight  int64 // records without a valid weight
+	badToken   int64 // non-numeric tokens (with -tokens)
+
+	tok int // with -tokens, the index in rec of the next token
+}
+
+func newNumberReader(opts *inputOptions, names []string) (*numberReader, error) {
+	units, err := newUnitParser(opts.unit)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	nr := &numberReader{recordReader: newRecordReader(opts, names), units: units}
+	if opts.tokens {
+		if opts.field != "" || opts.key != "" || opts.weighted {
+			return nil, errors.New("-tokens cannot be used with -field, -weighted, or grouping")
+		}
+		return nr, nil
+	}
+	if opts.split() {
+		spec := opts.field
+		switch {
+		case spec != "":
+		case opts.hasGroup("value"):
+			spec = "value"
+		default:
+			spec = "1"
+		}
+		col, err := nr.column(spec)
+		if err != nil {
+			return nil, err
+		}
+		nr.col = col
+	}
+	if opts.key != "" {
+		col, err := nr.column(opts.key)
+		if err != nil {
+			return nil, err
+		}
+		nr.keyCol = col
+	}
+	if opts.weighted {
+		col, err := nr.column(opts.weightField)
+		if err != nil {
+			return nil, err
+		}
+		nr.weightCol = col
+	}
+	return nr, nil
+}
+
+// Scan advances to the next record containing a number, which is then
+// available through Value.
+func (nr *numberReader) Scan() bool {
+	if nr.opts.tokens {
+		return nr.scanToken()
+	}
+	for nr.recordReader.Scan() {
+		if nr.col == nil {
+			v, err := nr.units.parse(nr.rec[0])
+			if err != nil {
+				nr.nonNumeric++
+				continue
+			}
+			nr.v = v
+			nr.weight = 1
+			return true
+		}
+		s, ok := nr.col.get(nr.rec)
+		if !ok {
+			nr.missing++
+			continue
+		}
+		v, err := nr.units.parse(strings.TrimSpace(s))
+		if err != nil {
+			nr.badField++
+			continue
+		}
+		if nr.keyCol != nil {
+			key, ok := nr.keyCol.get(nr.rec)
+			if !ok {
+				nr.missingKey++
+				continue
+			}
+			nr.key = key
+		}
+		nr.weight = 1
+		if nr.weightCol != nil {
+			s, _ := nr.weightCol.get(nr.rec)
+			w, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil || !(w >= 0) || math.IsInf(w, 1) {
+				nr.badWeight++
+				continue
+			}
+			nr.weight = w
+		}
+		nr.v = v
+		return true
+	}
+	return false
+}
+
+// scanToken is Scan for -tokens: it advances to the next numeric field of
+// any record.
+func (nr *numberReader) scanToken() bool {
+	for {
+		for nr.tok < len(nr.rec) {
+			s := strings.TrimSpace(nr.rec[nr.tok])
+			nr.tok++
+			if s == "" {
+				continue
+			}
+			v, err := nr.units.parse(s)
+			if err != nil {
+				nr.badToken++
+				continue
+			}
+			nr.v = v
+			nr.weight = 1
+			return true
+		}
+		if !nr.recordReader.Scan() {
+			return false
+		}
+		nr.tok = 0
+	}
+}
+
+// Value returns the number read by the most recent call to Scan.
+func (nr *numberReader) Value() float64 { return nr.v }
+
+// Unit returns the unit of the numbers read so far. With -unit auto, it is
+// unitNone until a number with a unit suffix is read.
+func (nr *numberReader) Unit() unit { return nr.units.unit }
+
+// Key returns the key of the record read by the most recent call to Scan.
+func (nr *numberReader) Key() string { return nr.key }
+
+// Weight returns the weight of the number read by the most recent call to
+// Scan, which is 1 if the input is unweighted.
+func (nr *numberReader) Weight() float64 { return nr.weight }
+
+// warn logs warnings about any skipped records.
+func (nr *numberReader) warn() {
+	if nr.noMatch > 0 {
+		log.Printf("warning: found %d lines that don't match -re", nr.noMatch)
+	}
+	if nr.nonNumeric > 0 {
+		log.Printf("warning: found %d non-numeric lines of input", nr.nonNumeric)
+	}
+	if nr.missing > 0 {
+		log.Printf("warning: found %d records without field %s", nr.missing, nr.col.spec)
+	}
+	if nr.badField > 0 {
+		log.Printf("warning: found %d records where field %s is not numeric", nr.badField, nr.col.spec)
+	}
+	if nr.missingKey > 0 {
+		log.Printf("warning: found %d records without key field %s", nr.missingKey, nr.keyCol.spec)
+	}
+	if nr.badToken > 0 {
+		log.Printf("warning: found %d non-numeric tokens", nr.badToken)
+	}
+	if nr.badWeight > 0 {
+		log.Printf("warning: found %d records without a valid weight in field %s", nr.badWeight, nr.weightCol.spec)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNumberReader(t *testing.T) {
+	// skipped holds the counts of the records that numberReader skips.
+	type skipped struct {
+		noMatch, nonNumeric, missing, badField, missingKey, badWeight, badToken int64
+	}
+	for _, tt := range []struct {
+		name    string
+		opts    inputOptions
+		input   string
+		values  []float64
+		weights []float64 // nil means all 1
+		keys    []string  // nil means no keys
+		skipped skipped
+	}{
+		{
+			name:    "lines",
+			input:   "1\n2.5\nfoo\n\n3\n",
+			values:  []float64{1, 2.5, 3},
+			skipped: skipped{nonNumeric: 1},
+		},
+		{
+			name:    "field",
+			opts:    inputOptions{field: "2"},
+			input:   "a 1\nb\nc x\nd   4\n",
+			values:  []float64{1, 4},
+			skipped: skipped{missing: 1, badField: 1},
+		},
+		{
+			name:   "header",
+			opts:   inputOptions{field: "lat", header: true},
+			input:  "name lat\nx 10\ny 20\n",
+			values: []float64{10, 20},
+		},
+		{
+			name:   "csv",
+			opts:   inputOptions{field: "lat", csv: true, header: true},
+			input:  "name,lat\n\"a, b\",1\n\"c\",\" 2 \"\n",
+			values: []float64{1, 2},
+		},
+		{
+			name:   "delim",
+			opts:   inputOptions{field: "2", delim: ";"},
+			input:  "a b;1\nc;2\n",
+			values: []float64{1, 2},
+		},
+		{
+			name:    "weighted",
+			opts:    inputOptions{field: "1", weighted: true, weightField: "2"},
+			input:   "1 2\n2 0.5\n3 x\n4 -1\n5\n",
+			values:  []float64{1, 2},
+			weights: []float64{2, 0.5},
+			skipped: skipped{badWeight: 3},
+		},
+		{
+			name:    "key",
+			opts:    inputOptions{field: "1", key: "3"},
+			input:   "1 x a\n2 x\n3 x b\n",
+			values:  []float64{1, 3},
+			keys:    []string{"a", "b"},
+			skipped: skipped{missingKey: 1},
+		},
+		{
+			name:    "re",
+			opts:    inputOptions{re: regexp.MustCompile(`(?P<key>\w+)=(?P<value>\S+)`), key: "key"},
+			input:   "a=1\nnope\nb=x\nc=3\n",
+			values:  []float64{1, 3},
+			keys:    []string{"a", "c"},
+			skipped: skipped{noMatch: 1, badField: 1},
+		},
+		{
+			name:   "re without groups",
+			opts:   inputOptions{re: regexp.MustCompile(`[0-9.]+`)},
+			input:  "took 12ms\ntook 3.5ms\n",
+			values: []float64{12, 3.5},
+		},
+		{
+			name:    "tokens",
+			opts:    inputOptions{tokens: true},
+			input:   "1 2 x\n\n3\n",
+			values:  []float64{1, 2, 3},
+			skipped: skipped{badToken: 1},
+		},
+		{
+			name:   "unit",
+			opts:   inputOptions{unit: "duration"},
+			input:  "1s\n500ms\n",
+			values: []float64{1, 0.5},
+		},
+	} {
+		opts := tt.opts
+		nr, err := newNumberReader(&opts, nil)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		nr.stdin = strings.NewReader(tt.input)
+		var (
+			values, weights []float64
+			keys            []string
+		)
+		for nr.Scan() {
+			values = append(values, nr.Value())
+			weights = append(weights, nr.Weight())
+			if tt.keys != nil {
+				keys = append(keys, nr.Key())
+			}
+		}
+		if err := nr.Err(); err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		wantWeights := tt.weights
+		if wantWeights == nil {
+			for range tt.values {
+				wantWeights = append(wantWeights, 1)
+			}
+		}
+		if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("%s: got values %v; want %v", tt.name, values, tt.values)
+		}
+		if !reflect.DeepEqual(weights, wantWeights) {
+			t.Errorf("%s: got weights %v; want %v", tt.name, weights, wantWeights)
+		}
+		if !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%s: got keys %q; want %q", tt.name, keys, tt.keys)
+		}
+		got := skipped{nr.noMatch, nr.nonNumeric, nr.missing, nr.badField, nr.missingKey, nr.badWeight, nr.badToken}
+		if got != tt.skipped {
+			t.Errorf("%s: got skipped records %+v; want %+v", tt.name, got, tt.skipped)
+		}
+
+		// warn logs a line for each kind of skipped record.
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		nr.warn()
+		log.SetOutput(os.Stderr)
+		want := 0
+		for _, n := range []int64{got.noMatch, got.nonNumeric, got.missing, got.badField, got.missingKey, got.badWeight, got.badToken} {
+			if n > 0 {
+				want++
+			}
+		}
+		if n := strings.Count(buf.String(), "warning: "); n != want {
+			t.Errorf("%s: got %d warnings (%q); want %d", tt.name, n, buf.String(), want)
+		}
+	}
+}
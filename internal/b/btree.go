@@ -118,7 +118,7 @@ type (
 
 	de struct { // d element
 		k float64
-		v int64
+		v float64
 	}
 
 	// Enumerator captures the state of enumerating a tree. It is returned
@@ -147,11 +147,22 @@ type (
 		last  *d
 		r     interface{}
 		ver   int64
+
+		// State for maintaining the subtree sums during a mutation.
+		path  []pathElem // the index pages visited and the child taken
+		delta float64    // change in the sum of the values
+		moved bool       // whether items or pages moved between pages
 	}
 
 	xe struct { // x element
 		ch interface{}
 		k  float64
+		s  float64 // sum of the values in the subtree ch
+	}
+
+	pathElem struct {
+		q *x
+		i int
 	}
 
 	x struct { // index page
@@ -197,6 +208,7 @@ func (q *x) extract(i int) {
 	if i < q.c {
 		copy(q.x[i:], q.x[i+1:q.c+1])
 		q.x[q.c].ch = q.x[q.c+1].ch
+		q.x[q.c].s = q.x[q.c+1].s
 		q.x[q.c].k = zk  // GC
 		q.x[q.c+1] = zxe // GC
 	}
@@ -206,6 +218,7 @@ func (q *x) insert(i int, k float64, ch interface{}) *x {
 	c := q.c
 	if i < c {
 		q.x[c+1].ch = q.x[c].ch
+		q.x[c+1].s = q.x[c].s
 		copy(q.x[i+2:], q.x[i+1:c])
 		q.x[i+1].k = q.x[i].k
 	}
@@ -273,6 +286,7 @@ func (t *Tree) Close() {
 
 func (t *Tree) cat(p *x, q, r *d, pi int) {
 	t.ver++
+	t.moved = true
 	q.mvL(r, r.c)
 	if r.n != nil {
 		r.n.p = q
@@ -300,10 +314,12 @@ func (t *Tree) cat(p *x, q, r *d, pi int) {
 
 func (t *Tree) catX(p, q, r *x, pi int) {
 	t.ver++
+	t.moved = true
 	q.x[q.c].k = p.x[pi].k
 	copy(q.x[q.c+1:], r.x[:r.c])
 	q.c += r.c + 1
 	q.x[q.c].ch = r.x[r.c].ch
+	q.x[q.c].s = r.x[r.c].s
 	*r = zx
 	btXPool.Put(r)
 	if p.c > 1 {
@@ -313,6 +329,7 @@ func (t *Tree) catX(p, q, r *x, pi int) {
 			p.x[pi].k = p.x[pi+1].k
 			copy(p.x[pi+1:], p.x[pi+2:pc+1])
 			p.x[pc].ch = p.x[pc+1].ch
+			p.x[pc].s = p.x[pc+1].s
 			p.x[pc].k = zk     // GC
 			p.x[pc+1].ch = nil // GC
 		}
@@ -333,6 +350,8 @@ func (t *Tree) catX(p, q, r *x, pi int) {
 // Delete removes the k's KV pair, if it exists, in which case Delete returns
 // true.
 func (t *Tree) Delete(k float64) (ok bool) {
+	t.begin()
+	defer t.end(k)
 	pi := -1
 	var p *x
 	q := t.r
@@ -351,10 +370,12 @@ func (t *Tree) Delete(k float64) (ok bool) {
 				}
 				pi = i + 1
 				p = x
+				t.path = append(t.path, pathElem{x, pi})
 				q = x.x[pi].ch
 				ok = false
 				continue
 			case *d:
+				t.delta = -x.d[i].v
 				t.extract(x, i)
 				if x.c >= kd {
 					return true
@@ -376,6 +397,7 @@ func (t *Tree) Delete(k float64) (ok bool) {
 			}
 			pi = i
 			p = x
+			t.path = append(t.path, pathElem{x, pi})
 			q = x.x[i].ch
 		case *d:
 			return false
@@ -433,7 +455,7 @@ func (t *Tree) find(q interface{}, k float64) (i int, ok bool) {
 
 // First returns the first item of the tree in the key collating order, or
 // (zero-value, zero-value) if the tree is empty.
-func (t *Tree) First() (k float64, v int64) {
+func (t *Tree) First() (k float64, v float64) {
 	if q := t.first; q != nil {
 		q := &q.d[0]
 		k, v = q.k, q.v
@@ -443,7 +465,7 @@ func (t *Tree) First() (k float64, v int64) {
 
 // Get returns the value associated with k and true if it exists. Otherwise Get
 // returns (zero-value, false).
-func (t *Tree) Get(k float64) (v int64, ok bool) {
+func (t *Tree) Get(k float64) (v float64, ok bool) {
 	q := t.r
 	if q == nil {
 		return
@@ -469,7 +491,7 @@ func (t *Tree) Get(k float64) (v int64, ok bool) {
 	}
 }
 
-func (t *Tree) insert(q *d, i int, k float64, v int64) *d {
+func (t *Tree) insert(q *d, i int, k float64, v float64) *d {
 	t.ver++
 	c := q.c
 	if i < c {
@@ -484,7 +506,7 @@ func (t *Tree) insert(q *d, i int, k float64, v int64) *d {
 
 // Last returns the last item of the tree in the key collating order, or
 // (zero-value, zero-value) if the tree is empty.
-func (t *Tree) Last() (k float64, v int64) {
+func (t *Tree) Last() (k float64, v float64) {
 	if q := t.last; q != nil {
 		q := &q.d[q.c-1]
 		k, v = q.k, q.v
@@ -497,11 +519,12 @@ func (t *Tree) Len() int {
 	return t.c
 }
 
-func (t *Tree) overflow(p *x, q *d, pi, i int, k float64, v int64) {
+func (t *Tree) overflow(p *x, q *d, pi, i int, k float64, v float64) {
 	t.ver++
+	t.moved = true
 	l, r := p.siblings(pi)
 
-	if l != nil && l.c < 2*kd {
+	if l != nil && l.c < 2*kd && i != 0 {
 		l.mvL(q, 1)
 		t.insert(q, i-1, k, v)
 		p.x[pi-1].k = q.d[0].k
@@ -577,12 +600,14 @@ func (t *Tree) SeekLast() (e *Enumerator, err error) {
 }
 
 // Set sets the value associated with k.
-func (t *Tree) Set(k float64, v int64) {
+func (t *Tree) Set(k float64, v float64) {
 	//dbg("--- PRE Set(%v, %v)\n%s", k, v, t.dump())
 	//defer func() {
 	//	dbg("--- POST\n%s\n====\n", t.dump())
 	//}()
 
+	t.begin()
+	defer t.end(k)
 	pi := -1
 	var p *x
 	q := t.r
@@ -602,9 +627,11 @@ func (t *Tree) Set(k float64, v int64) {
 				}
 				pi = i + 1
 				p = x
+				t.path = append(t.path, pathElem{x, pi})
 				q = x.x[i+1].ch
 				continue
 			case *d:
+				t.delta = v - x.d[i].v
 				x.d[i].v = v
 			}
 			return
@@ -617,8 +644,10 @@ func (t *Tree) Set(k float64, v int64) {
 			}
 			pi = i
 			p = x
+			t.path = append(t.path, pathElem{x, pi})
 			q = x.x[i].ch
 		case *d:
+			t.delta = v
 			switch {
 			case x.c < 2*kd:
 				t.insert(x, i, k, v)
@@ -642,11 +671,13 @@ func (t *Tree) Set(k float64, v int64) {
 // 	tree.Put(k, func(float64, bool){ return v, true })
 //
 // modulo the differing return values.
-func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, write bool)) (oldV int64, written bool) {
+func (t *Tree) Put(k float64, upd func(oldV float64, exists bool) (newV float64, write bool)) (oldV float64, written bool) {
+	t.begin()
+	defer t.end(k)
 	pi := -1
 	var p *x
 	q := t.r
-	var newV int64
+	var newV float64
 	if q == nil {
 		// new KV pair in empty tree
 		newV, written = upd(newV, false)
@@ -669,6 +700,7 @@ func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, wri
 				}
 				pi = i + 1
 				p = x
+				t.path = append(t.path, pathElem{x, pi})
 				q = x.x[i+1].ch
 				continue
 			case *d:
@@ -678,6 +710,7 @@ func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, wri
 					return
 				}
 
+				t.delta = newV - oldV
 				x.d[i].v = newV
 			}
 			return
@@ -690,6 +723,7 @@ func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, wri
 			}
 			pi = i
 			p = x
+			t.path = append(t.path, pathElem{x, pi})
 			q = x.x[i].ch
 		case *d: // new KV pair
 			newV, written = upd(newV, false)
@@ -697,6 +731,7 @@ func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, wri
 				return
 			}
 
+			t.delta = newV
 			switch {
 			case x.c < 2*kd:
 				t.insert(x, i, k, newV)
@@ -708,8 +743,127 @@ func (t *Tree) Put(k float64, upd func(oldV int64, exists bool) (newV int64, wri
 	}
 }
 
-func (t *Tree) split(p *x, q *d, pi, i int, k float64, v int64) {
+// begin resets the state used to maintain the subtree sums during a
+// mutation.
+func (t *Tree) begin() {
+	t.path = t.path[:0]
+	t.delta = 0
+	t.moved = false
+}
+
+// end brings the subtree sums up to date after a mutation involving the key
+// k. If no items or pages moved between pages, only the sums along the path
+// to k changed, and all by the same amount. Otherwise, every page that was
+// restructured is on the path to k or adjacent to a page on it, so end
+// recomputes the sums of those pages from the bottom up.
+func (t *Tree) end(k float64) {
+	if !t.moved {
+		for _, e := range t.path {
+			e.q.x[e.i].s += t.delta
+		}
+		return
+	}
+
+	t.path = t.path[:0]
+	for q := t.r; ; {
+		x, ok := q.(*x)
+		if !ok {
+			break
+		}
+
+		i, ok := t.find(x, k)
+		if ok {
+			i++
+		}
+		t.path = append(t.path, pathElem{x, i})
+		q = x.x[i].ch
+	}
+	for j := len(t.path) - 1; j >= 0; j-- {
+		e := t.path[j]
+		for i := e.i - 1; i <= e.i+1; i++ {
+			if i >= 0 && i <= e.q.c {
+				e.q.x[i].s = sum(e.q.x[i].ch)
+			}
+		}
+	}
+}
+
+// sum returns the sum of the values in the subtree q, using the sums of its
+// children if q is an index page.
+func sum(q interface{}) (s float64) {
+	switch x := q.(type) {
+	case *x:
+		for i := 0; i <= x.c; i++ {
+			s += x.x[i].s
+		}
+	case *d:
+		for i := 0; i < x.c; i++ {
+			s += x.d[i].v
+		}
+	}
+	return s
+}
+
+// Sum returns the sum of all the values in the tree.
+func (t *Tree) Sum() float64 {
+	return sum(t.r)
+}
+
+// Rank returns the sum of the values of the items whose keys are less than
+// or equal to k. It takes O(log n) time.
+func (t *Tree) Rank(k float64) (s float64) {
+	q := t.r
+	for q != nil {
+		i, ok := t.find(q, k)
+		if ok {
+			i++
+		}
+		switch x := q.(type) {
+		case *x:
+			for j := 0; j < i; j++ {
+				s += x.x[j].s
+			}
+			q = x.x[i].ch
+		case *d:
+			for j := 0; j < i; j++ {
+				s += x.d[j].v
+			}
+			return s
+		}
+	}
+	return s
+}
+
+// Select returns the first item, in key order, at which the running sum of
+// the values exceeds s. If every value is 1, Select(r) returns the item of
+// (0-based) rank r. The result is ok only if the sum of all the values
+// exceeds s. It takes O(log n) time.
+func (t *Tree) Select(s float64) (k float64, v float64, ok bool) {
+	q := t.r
+	for q != nil {
+		switch x := q.(type) {
+		case *x:
+			i := 0
+			for ; i < x.c && s >= x.x[i].s; i++ {
+				s -= x.x[i].s
+			}
+			q = x.x[i].ch
+		case *d:
+			for i := 0; i < x.c; i++ {
+				if s < x.d[i].v {
+					return x.d[i].k, x.d[i].v, true
+				}
+				s -= x.d[i].v
+			}
+			return k, v, false
+		}
+	}
+	return k, v, false
+}
+
+func (t *Tree) split(p *x, q *d, pi, i int, k float64, v float64) {
 	t.ver++
+	t.moved = true
 	r := btDPool.Get().(*d)
 	if q.n != nil {
 		r.n = q.n
@@ -745,6 +899,7 @@ func (t *Tree) split(p *x, q *d, pi, i int, k float64, v int64) {
 
 func (t *Tree) splitX(p *x, q *x, pi int, i int) (*x, int) {
 	t.ver++
+	t.moved = true
 	r := btXPool.Get().(*x)
 	copy(r.x[:], q.x[kx+1:])
 	q.c = kx
@@ -785,6 +940,7 @@ func (t *Tree) splitX(p *x, q *x, pi int, i int) (*x, int) {
 
 func (t *Tree) underflow(p *x, q *d, pi int) {
 	t.ver++
+	t.moved = true
 	l, r := p.siblings(pi)
 
 	if l != nil && l.c+q.c >= 2*kd {
@@ -803,6 +959,7 @@ func (t *Tree) underflow(p *x, q *d, pi int) {
 
 func (t *Tree) underflowX(p *x, q *x, pi int, i int) (*x, int) {
 	t.ver++
+	t.moved = true
 	var l, r *x
 
 	if pi >= 0 {
@@ -816,8 +973,10 @@ func (t *Tree) underflowX(p *x, q *x, pi int, i int) (*x, int) {
 
 	if l != nil && l.c > kx {
 		q.x[q.c+1].ch = q.x[q.c].ch
+		q.x[q.c+1].s = q.x[q.c].s
 		copy(q.x[1:], q.x[:q.c])
 		q.x[0].ch = l.x[l.c].ch
+		q.x[0].s = l.x[l.c].s
 		q.x[0].k = p.x[pi-1].k
 		q.c++
 		i++
@@ -830,11 +989,13 @@ func (t *Tree) underflowX(p *x, q *x, pi int, i int) (*x, int) {
 		q.x[q.c].k = p.x[pi].k
 		q.c++
 		q.x[q.c].ch = r.x[0].ch
+		q.x[q.c].s = r.x[0].s
 		p.x[pi].k = r.x[0].k
 		copy(r.x[:], r.x[1:r.c])
 		r.c--
 		rc := r.c
 		r.x[rc].ch = r.x[rc+1].ch
+		r.x[rc].s = r.x[rc+1].s
 		r.x[rc].k = zk
 		r.x[rc+1].ch = nil
 		return q, i
@@ -863,7 +1024,7 @@ func (e *Enumerator) Close() {
 // Next returns the currently enumerated item, if it exists and moves to the
 // next item in the key collation order. If there is no item to return, err ==
 // io.EOF is returned.
-func (e *Enumerator) Next() (k float64, v int64, err error) {
+func (e *Enumerator) Next() (k float64, v float64, err error) {
 	if err = e.err; err != nil {
 		return
 	}
@@ -917,7 +1078,7 @@ func (e *Enumerator) next() error {
 // Prev returns the currently enumerated item, if it exists and moves to the
 // previous item in the key collation order. If there is no item to return, err
 // == io.EOF is returned.
-func (e *Enumerator) Prev() (k float64, v int64, err error) {
+func (e *Enumerator) Prev() (k float64, v float64, err error) {
 	if err = e.err; err != nil {
 		return
 	}
@@ -0,0 +1,138 @@
+package b
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func cmpFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+// checkSums verifies that every index page entry holds the sum of the values
+// in its subtree and returns the sum of the values under q.
+func checkSums(t *testing.T, q interface{}) float64 {
+	t.Helper()
+	switch x := q.(type) {
+	case *x:
+		var s float64
+		for i := 0; i <= x.c; i++ {
+			got := checkSums(t, x.x[i].ch)
+			if got != x.x[i].s {
+				t.Fatalf("index entry %d has sum %g; subtree sums to %g", i, x.x[i].s, got)
+			}
+			s += got
+		}
+		return s
+	case *d:
+		var s float64
+		for i := 0; i < x.c; i++ {
+			s += x.d[i].v
+		}
+		return s
+	}
+	return 0
+}
+
+func TestRankSelect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tr := TreeNew(cmpFloat)
+	m := make(map[float64]float64)
+	const nkeys = 10000
+	for i := 0; i < 100000; i++ {
+		k := float64(rng.Intn(nkeys))
+		// Lean toward insertions at first to grow a deep tree, then
+		// toward deletions to shrink it again.
+		switch r := rng.Intn(10); {
+		case r < 6 && i < 50000 || r < 3:
+			v := float64(rng.Intn(5) + 1)
+			tr.Set(k, v)
+			m[k] = v
+		case r < 8 && i < 50000 || r < 5:
+			tr.Put(k, func(old float64, _ bool) (float64, bool) { return old + 1, true })
+			m[k]++
+		default:
+			tr.Delete(k)
+			delete(m, k)
+		}
+		if i%5000 == 0 {
+			checkTree(t, tr, m)
+		}
+	}
+	checkTree(t, tr, m)
+}
+
+func checkTree(t *testing.T, tr *Tree, m map[float64]float64) {
+	t.Helper()
+	checkSums(t, tr.r)
+	keys := make([]float64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	var cum float64
+	for _, k := range keys {
+		if got, want := tr.Rank(k-0.5), cum; got != want {
+			t.Fatalf("Rank(%g): got %g; want %g", k-0.5, got, want)
+		}
+		for _, s := range []float64{cum, cum + m[k] - 0.5} {
+			gk, gv, ok := tr.Select(s)
+			if !ok || gk != k || gv != m[k] {
+				t.Fatalf("Select(%g): got (%g, %g, %t); want (%g, %g, true)", s, gk, gv, ok, k, m[k])
+			}
+		}
+		cum += m[k]
+		if got := tr.Rank(k); got != cum {
+			t.Fatalf("Rank(%g): got %g; want %g", k, got, cum)
+		}
+	}
+	if got := tr.Sum(); got != cum {
+		t.Fatalf("Sum: got %g; want %g", got, cum)
+	}
+	if _, _, ok := tr.Select(cum); ok {
+		t.Fatalf("Select(%g) succeeded with a total of %g", cum, cum)
+	}
+}
+
+func TestSetFirstOfFullPage(t *testing.T) {
+	// Insert a key at the start of a full data page whose left sibling has
+	// room. The key belongs before everything in the page, so none of the
+	// page's items can move to the sibling to make room for it (overflow
+	// must split the page instead).
+	tr := TreeNew(cmpFloat)
+	m := make(map[float64]float64)
+	set := func(k float64) {
+		tr.Set(k, 1)
+		m[k] = 1
+	}
+	for k := 0; k <= 2*kd; k++ {
+		set(float64(k))
+	}
+	root, ok := tr.r.(*x)
+	if !ok || root.c != 1 {
+		t.Fatalf("after %d insertions, want a root index page with two children", 2*kd+1)
+	}
+	l, r := root.x[0].ch.(*d), root.x[1].ch.(*d)
+	for k := r.d[r.c-1].k + 1; r.c < 2*kd; k++ {
+		set(k)
+	}
+	// Deleting the first key of the right page leaves a gap between the
+	// separator and the page's new first key.
+	first := r.d[0].k
+	tr.Delete(first)
+	delete(m, first)
+	set(r.d[r.c-1].k + 1)
+	if l.c >= 2*kd || r.c != 2*kd || root.x[0].k != first {
+		t.Fatalf("got pages of %d and %d items and separator %g; want room on the left, a full right page, and separator %g",
+			l.c, r.c, root.x[0].k, first)
+	}
+	set(first + 0.5)
+	checkTree(t, tr, m)
+}
@@ -0,0 +1,117 @@
+// Package dist implements the probability distributions needed for
+// hypothesis tests and confidence intervals.
+package dist
+
+import "math"
+
+// NormalCDF returns the probability that a standard normal random variable
+// is less than or equal to x.
+func NormalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// NormalQuantile is the inverse of NormalCDF. It returns -Inf for p = 0 and
+// +Inf for p = 1.
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// StudentTCDF returns the probability that a random variable with Student's
+// t-distribution with df degrees of freedom is less than or equal to t.
+func StudentTCDF(t, df float64) float64 {
+	if math.IsInf(t, 0) {
+		if t > 0 {
+			return 1
+		}
+		return 0
+	}
+	p := 0.5 * RegIncBeta(df/2, 0.5, df/(df+t*t))
+	if t > 0 {
+		return 1 - p
+	}
+	return p
+}
+
+// BinomialCDF returns the probability that a binomial random variable with
+// n trials and success probability p is less than or equal to k.
+func BinomialCDF(k, n, p float64) float64 {
+	k = math.Floor(k)
+	switch {
+	case k < 0:
+		return 0
+	case k >= n:
+		return 1
+	}
+	return RegIncBeta(n-k, k+1, 1-p)
+}
+
+// RegIncBeta returns the regularized incomplete beta function I_x(a, b).
+func RegIncBeta(a, b, x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	// The continued fraction converges quickly for x < (a+1)/(a+b+2);
+	// otherwise use the symmetry I_x(a, b) = 1 - I_{1-x}(b, a).
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(a, b, x) / a
+	}
+	return 1 - front*betaCF(b, a, 1-x)/b
+}
+
+// betaCF evaluates the continued fraction for the incomplete beta function
+// using the modified Lentz method (see Numerical Recipes, §6.4).
+func betaCF(a, b, x float64) float64 {
+	const (
+		maxIter = 300
+		eps     = 1e-15
+		tiny    = 1e-300
+	)
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
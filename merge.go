@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cespare/stats/summary"
+)
+
+func merge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s merge [flags] STATEFILE...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Combine summarizer state files written by 'stats summarize -save'.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	sumOpts := addSummaryFlags(fs)
+	save := fs.String("save", "", "Also write the merged state to this file")
+	unitStr := fs.String("unit", "", "Print values in this unit (state files don't record it): duration, bytes, or si")
+	fs.Parse(args)
+
+	up, err := newUnitParser(*unitStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if up.auto {
+		log.Fatal("-unit auto cannot be used with merge")
+	}
+	if err := sumOpts.parse(up); err != nil {
+		log.Fatal(err)
+	}
+	sumOpts.unit = up.unit
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		parts       []*summary.Summarizer
+		approx      bool
+		compression float64
+	)
+	for _, name := range fs.Args() {
+		sr, err := loadSummarizer(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if sr.Approx() {
+			approx = true
+			if c := sr.Compression(); c > compression {
+				compression = c
+			}
+		}
+		parts = append(parts, sr)
+	}
+
+	if approx && sumOpts.ci > 0 {
+		log.Fatal("-ci cannot be used with approximate state (bootstrapping needs every value)")
+	}
+	if err := sumOpts.checkStats(approx); err != nil {
+		log.Fatal(err)
+	}
+
+	// If any of the inputs are approximate, so is the result.
+	merged := summary.New()
+	if approx {
+		merged = summary.NewApprox(compression)
+	}
+	merged.SetQuantileMethod(sumOpts.method)
+	for _, sr := range parts {
+		if err := merged.Merge(sr); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *save != "" {
+		if err := saveSummarizer(*save, merged); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if merged.Count() == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	if err := sumOpts.write(os.Stdout, merged); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func saveSummarizer(name string, sr *summary.Summarizer) error {
+	b, err := sr.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, b, 0o644)
+}
+
+func loadSummarizer(name string) (*summary.Summarizer, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var sr summary.Summarizer
+	if err := sr.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return &sr, nil
+}
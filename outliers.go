@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cespare/stats/summary"
+)
+
+func outliers(args []string) {
+	fs := flag.NewFlagSet("outliers", flag.ExitOnError)
+	methodStr := fs.String("method", "iqr", outlierMethodUsage)
+	k := fs.Float64("threshold", 0, outlierThresholdUsage)
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	method, err := parseOutlierMethod(*methodStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *k < 0 {
+		log.Fatalf("-threshold must not be negative; got %g", *k)
+	}
+	if *k == 0 {
+		*k = method.DefaultThreshold()
+	}
+
+	if err := printOutliers(inOpts, fs.Args(), method, *k, format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printOutliers prints the records of the named inputs whose numbers method
+// flags as outliers with threshold k. Errors are returned rather than
+// fatal so that the temporary copy of stdin is always removed.
+func printOutliers(inOpts *inputOptions, names []string, method summary.OutlierMethod, k float64, format outputFormat) error {
+	// The input is read twice: once to find the fences and again to find
+	// the values outside of them. Standard input is saved to a temporary
+	// file for the second pass.
+	var stdin *os.File
+	if readsStdin(names) {
+		var err error
+		stdin, err = spoolStdin()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(stdin.Name())
+		defer stdin.Close()
+	}
+	newReader := func() (*numberReader, error) {
+		nr, err := newNumberReader(inOpts, names)
+		if err != nil {
+			return nil, err
+		}
+		if stdin != nil {
+			if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			nr.stdin = stdin
+		}
+		return nr, nil
+	}
+
+	nr, err := newReader()
+	if err != nil {
+		return err
+	}
+	sr := summary.New()
+	for nr.Scan() {
+		sr.AddWeighted(nr.Value(), nr.Weight())
+	}
+	if err := nr.Err(); err != nil {
+		return err
+	}
+	nr.warn()
+	if sr.Count() == 0 {
+		log.Println("no numbers given")
+		return nil
+	}
+	u := nr.Unit()
+	lo, hi := sr.OutlierFences(method, k)
+
+	var rows [][]stat
+	if nr, err = newReader(); err != nil {
+		return err
+	}
+	for nr.Scan() {
+		v := nr.Value()
+		if v >= lo && v <= hi {
+			continue
+		}
+		text := nr.text
+		if inOpts.csv {
+			delim := ","
+			if inOpts.delim != "" {
+				delim = inOpts.delim
+			}
+			text = strings.Join(nr.Record(), delim)
+		}
+		rows = append(rows, []stat{
+			{"file", "file", nr.name},
+			{"line", "line", int64(nr.line)},
+			{"value", "value", unitValue(v, u)},
+			{"text", "text", text},
+		})
+	}
+	if err := nr.Err(); err != nil {
+		return err
+	}
+	log.Printf("found %d outliers outside [%s, %s] (%s, threshold %g)",
+		len(rows), formatBound(lo, u), formatBound(hi, u), method, k)
+	if len(rows) == 0 {
+		return nil
+	}
+	return writeRows(os.Stdout, format, rows)
+}
+
+const (
+	outlierMethodUsage = "Outlier rule: iqr (beyond the quartiles by a multiple of the interquartile range), " +
+		"mad (a modified z-score using the median absolute deviation), or zscore (a z-score using the mean and std. dev.)"
+	outlierThresholdUsage = "The multiple of the IQR, or the z-score, beyond which values are outliers " +
+		"(default 1.5 for iqr, 3.5 for mad, and 3 for zscore)"
+)
+
+// parseOutlierMethod parses the value of an -outliers or -method flag.
+func parseOutlierMethod(s string) (summary.OutlierMethod, error) {
+	for _, m := range []summary.OutlierMethod{summary.IQR, summary.MAD, summary.ZScore} {
+		if s == m.String() {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown outlier method %q (must be iqr, mad, or zscore)", s)
+}
+
+// readsStdin reports whether a list of input names includes stdin.
+func readsStdin(names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if name == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// spoolStdin copies stdin to a temporary file, which the caller must
+// remove.
+func spoolStdin() (*os.File, error) {
+	f, err := os.CreateTemp("", "stats-stdin-")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
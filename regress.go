@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cespare/stats/summary"
+)
+
+func regress(args []string) {
+	fs := flag.NewFlagSet("regress", flag.ExitOnError)
+	xSpec := fs.String("x", "", `Read x values from this field (default: the -re group named "x", or else 1)`)
+	ySpec := fs.String("y", "", `Read y values from this field (default: the -re group named "y", or else 2)`)
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	plot := fs.Bool("plot", false, "Draw a scatter plot of the values with the fitted line")
+	inOpts := addRecordFlags(fs)
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *plot && format != formatTable {
+		log.Fatal("-plot can only be used with the table format")
+	}
+	if *xSpec == "" {
+		*xSpec = "1"
+		if inOpts.hasGroup("x") {
+			*xSpec = "x"
+		}
+	}
+	if *ySpec == "" {
+		*ySpec = "2"
+		if inOpts.hasGroup("y") {
+			*ySpec = "y"
+		}
+	}
+
+	pr, err := newPairReader(inOpts, fs.Args(), *xSpec, *ySpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var xs, ys []float64
+	for pr.Scan() {
+		xs = append(xs, pr.x)
+		ys = append(ys, pr.y)
+	}
+	if err := pr.Err(); err != nil {
+		log.Fatal(err)
+	}
+	pr.warn()
+	if len(xs) == 0 {
+		log.Println("no pairs of numbers given")
+		return
+	}
+	fit, err := summary.FitLine(xs, ys)
+	if err != nil {
+		switch err {
+		case summary.ErrSampleSize:
+			log.Fatalf("need at least 3 pairs of numbers to fit a line; got %d", len(xs))
+		case summary.ErrConstant:
+			log.Fatal("cannot fit a line: every x value is the same")
+		}
+		log.Fatal(err)
+	}
+	ux, uy := pr.xUnits.unit, pr.yUnits.unit
+	if err := writeSummary(os.Stdout, format, regressionStats(xs, ys, fit, uy), nil, uy); err != nil {
+		log.Fatal(err)
+	}
+	if *plot {
+		fmt.Println()
+		fmt.Println(formatScatter(xs, ys, fit, ux, uy))
+	}
+}
+
+// regressionStats gives the correlations of the pairs (xs[i], ys[i]) and
+// the statistics of the fitted line, including a summary of its residuals.
+// Values in the units of y are given in the unit u. Correlations that are
+// undefined (because every y value is the same) are NaN.
+func regressionStats(xs, ys []float64, fit summary.LinearFit, u unit) []stat {
+	stats := []stat{{"count", "count", int64(fit.N)}}
+	for _, c := range []struct {
+		name, label string
+		corr        func(xs, ys []float64) (summary.Correlation, error)
+	}{
+		{"pearson", "Pearson's r", summary.Pearson},
+		{"spearman", "Spearman's ρ", summary.Spearman},
+		{"kendall", "Kendall's τ", summary.Kendall},
+	} {
+		r, err := c.corr(xs, ys)
+		if err != nil {
+			r = summary.Correlation{Coefficient: math.NaN(), P: math.NaN()}
+		}
+		stats = append(stats,
+			stat{c.name, c.label, r.Coefficient},
+			stat{c.name + "_p", c.label + " p-value", r.P},
+		)
+	}
+	stats = append(stats,
+		stat{"slope", "slope", fit.Slope},
+		stat{"slope_stderr", "slope std. err.", fit.SlopeErr},
+		stat{"slope_p", "slope p-value", fit.P},
+		stat{"intercept", "intercept", unitValue(fit.Intercept, u)},
+		stat{"intercept_stderr", "intercept std. err.", unitValue(fit.InterceptErr, u)},
+		stat{"r2", "R²", fit.R2},
+		stat{"residual_stderr", "residual std. err.", unitValue(fit.ResidualErr, u)},
+	)
+
+	residuals := summary.New()
+	for i := range xs {
+		residuals.Add(ys[i] - fit.Predict(xs[i]))
+	}
+	stats = append(stats,
+		stat{"residual_min", "residual min", unitValue(residuals.Min(), u)},
+	)
+	for _, q := range []float64{0.25, 0.5, 0.75} {
+		stats = append(stats, stat{
+			"residual_" + quantileName(q),
+			fmt.Sprintf("residual quantile %g", q),
+			unitValue(residuals.Quantile(q), u),
+		})
+	}
+	stats = append(stats,
+		stat{"residual_max", "residual max", unitValue(residuals.Max(), u)},
+	)
+	return stats
+}
+
+// A pairReader reads a pair of numbers, x and y, from each record of its
+// input, skipping and counting the records for which that isn't possible.
+type pairReader struct {
+	*recordReader
+	xCol, yCol     *column
+	xUnits, yUnits *unitParser
+	x, y           float64
+
+	missing  int64 // records without one of the fields
+	badField int64 // records where one of the fields is not numeric
+}
+
+func newPairReader(opts *inputOptions, names []string, xSpec, ySpec string) (*pairReader, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	if opts.key != "" {
+		return nil, errors.New("pairs of numbers cannot be grouped")
+	}
+	xUnits, err := newUnitParser(opts.unit)
+	if err != nil {
+		return nil, err
+	}
+	yUnits := *xUnits // x and y may have different units
+	opts.columns = true
+	pr := &pairReader{
+		recordReader: newRecordReader(opts, names),
+		xUnits:       xUnits,
+		yUnits:       &yUnits,
+	}
+	if pr.xCol, err = pr.column(xSpec); err != nil {
+		return nil, err
+	}
+	if pr.yCol, err = pr.column(ySpec); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// Scan advances to the next record containing a pair of numbers.
+func (pr *pairReader) Scan() bool {
+	for pr.recordReader.Scan() {
+		xs, ok1 := pr.xCol.get(pr.rec)
+		ys, ok2 := pr.yCol.get(pr.rec)
+		if !ok1 || !ok2 {
+			pr.missing++
+			continue
+		}
+		x, err1 := pr.xUnits.parse(strings.TrimSpace(xs))
+		y, err2 := pr.yUnits.parse(strings.TrimSpace(ys))
+		if err1 != nil || err2 != nil {
+			pr.badField++
+			continue
+		}
+		pr.x, pr.y = x, y
+		return true
+	}
+	return false
+}
+
+// warn logs warnings about any skipped records.
+func (pr *pairReader) warn() {
+	if pr.noMatch > 0 {
+		log.Printf("warning: found %d lines that don't match -re", pr.noMatch)
+	}
+	if pr.missing > 0 {
+		log.Printf("warning: found %d records without field %s or %s", pr.missing, pr.xCol.spec, pr.yCol.spec)
+	}
+	if pr.badField > 0 {
+		log.Printf("warning: found %d records where field %s or %s is not numeric", pr.badField, pr.xCol.spec, pr.yCol.spec)
+	}
+}
+
+// The size of a scatter plot, in characters. The width matches that of a
+// histogram's bars.
+const (
+	scatterCols = histBlocks
+	scatterRows = 20
+)
+
+// lineEighths are the blocks that draw a fitted line: each fills the bottom
+// of a character cell up to the height of the line within the cell.
+var lineEighths = [7]rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇'}
+
+// formatScatter draws a scatter plot of the pairs (xs[i], ys[i]), marking
+// each cell containing a point with a full block, along with the fitted
+// line. The axes are labeled in the units ux and uy.
+func formatScatter(xs, ys []float64, fit summary.LinearFit, ux, uy unit) string {
+	xMin, xMax := floatRange(xs)
+	yMin, yMax := floatRange(ys)
+	if yMin == yMax {
+		yMin--
+		yMax++
+	}
+	// cell gives the position of v within the n cells spanning [lo, hi],
+	// along with its fractional part.
+	cell := func(v, lo, hi float64, n int) (int, float64) {
+		f := (v - lo) / (hi - lo) * float64(n)
+		i := int(math.Floor(f))
+		if i == n && v == hi {
+			return n - 1, 1 // the top edge of the last cell
+		}
+		return i, f - float64(i)
+	}
+
+	grid := make([][]rune, scatterRows)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", scatterCols))
+	}
+	for c := 0; c < scatterCols; c++ {
+		x := xMin + (float64(c)+0.5)/scatterCols*(xMax-xMin)
+		r, frac := cell(fit.Predict(x), yMin, yMax, scatterRows)
+		if r < 0 || r >= scatterRows {
+			continue
+		}
+		i := int(frac * float64(len(lineEighths)))
+		if i == len(lineEighths) {
+			i--
+		}
+		grid[scatterRows-1-r][c] = lineEighths[i]
+	}
+	for i := range xs {
+		c, _ := cell(xs[i], xMin, xMax, scatterCols)
+		r, _ := cell(ys[i], yMin, yMax, scatterRows)
+		grid[scatterRows-1-r][c] = barEighths[8]
+	}
+
+	top, bottom := formatBound(yMax, uy), formatBound(yMin, uy)
+	labelWidth := utf8.RuneCountInString(top)
+	if n := utf8.RuneCountInString(bottom); n > labelWidth {
+		labelWidth = n
+	}
+	var buf bytes.Buffer
+	for i, row := range grid {
+		label := ""
+		switch i {
+		case 0:
+			label = top
+		case scatterRows - 1:
+			label = bottom
+		}
+		fmt.Fprintf(&buf, " %*s │%s\n", labelWidth, label, strings.TrimRight(string(row), " "))
+	}
+	writeAxis(&buf, labelWidth, xMin, xMax, ux, scatterCols)
+	return buf.String()
+}
+
+// floatRange returns the smallest and largest of vs.
+func floatRange(vs []float64) (lo, hi float64) {
+	lo, hi = vs[0], vs[0]
+	for _, v := range vs[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
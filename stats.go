@@ -14,6 +14,46 @@ var cmds = []subcmd.Command{
 		Description: "Display summary statistics for a sequence of numbers",
 		Do:          summarize,
 	},
+	{
+		Name:        "merge",
+		Description: "Combine summarizer state saved by summarize -save",
+		Do:          merge,
+	},
+	{
+		Name:        "compare",
+		Description: "Compare two sequences of numbers with significance tests",
+		Do:          compare,
+	},
+	{
+		Name:        "hist",
+		Description: "Display a histogram of a sequence of numbers",
+		Do:          hist,
+	},
+	{
+		Name:        "timeseries",
+		Description: "Display summary statistics for each window of time",
+		Do:          timeseries,
+	},
+	{
+		Name:        "cdf",
+		Description: "Display the empirical cumulative distribution of a sequence of numbers",
+		Do:          cdf,
+	},
+	{
+		Name:        "regress",
+		Description: "Display the correlation and linear fit of pairs of numbers",
+		Do:          regress,
+	},
+	{
+		Name:        "outliers",
+		Description: "Print the lines of input with outlying numbers",
+		Do:          outliers,
+	},
+	{
+		Name:        "boxplot",
+		Description: "Draw box plots or violin plots of sequences of numbers on a shared axis",
+		Do:          boxplot,
+	},
 }
 
 const version = "0.1.1"
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cespare/stats/summary"
+)
+
+// streamSummary reads numbers from nr in the background and prints a summary
+// to w every interval, as well as once more when the input is exhausted. If
+// window is nonzero, only the values read during (roughly) the most recent
+// window are summarized. It returns the final summary.
+func streamSummary(w *os.File, nr *numberReader, newSummarizer func() *summary.Summarizer, opts *summaryOptions, interval, window time.Duration) (*summary.Summarizer, error) {
+	win := newWindow(newSummarizer, interval, window)
+	var mu sync.Mutex // protects win and opts.unit
+	done := make(chan struct{})
+	go func() {
+		for nr.Scan() {
+			mu.Lock()
+			win.add(nr.Value(), nr.Weight())
+			opts.unit = nr.Unit()
+			mu.Unlock()
+		}
+		close(done)
+	}()
+
+	sp := &streamPrinter{w: w, tty: isTerminal(w), opts: opts}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case t := <-ticker.C:
+			mu.Lock()
+			sr := win.summarizer()
+			win.advance()
+			err := sp.print(sr, t)
+			mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+		case <-done:
+			if err := nr.Err(); err != nil {
+				return nil, err
+			}
+			sr := win.summarizer()
+			return sr, sp.print(sr, time.Now())
+		}
+	}
+}
+
+// A window is a sliding window of Summarizers, one per interval. The values
+// in the oldest Summarizer are dropped all at once, so the window covers
+// between n-1 and n intervals.
+type window struct {
+	newSummarizer func() *summary.Summarizer
+	slots         []*summary.Summarizer // oldest first
+	n             int                   // max len(slots); 0 means unbounded
+}
+
+func newWindow(newSummarizer func() *summary.Summarizer, interval, d time.Duration) *window {
+	w := &window{
+		newSummarizer: newSummarizer,
+		slots:         []*summary.Summarizer{newSummarizer()},
+	}
+	if d > 0 {
+		w.n = int((d + interval - 1) / interval)
+	}
+	return w
+}
+
+func (w *window) add(v, weight float64) {
+	w.slots[len(w.slots)-1].AddWeighted(v, weight)
+}
+
+// advance starts a new interval, discarding the oldest one if the window is
+// full.
+func (w *window) advance() {
+	if w.n == 0 {
+		return
+	}
+	w.slots = append(w.slots, w.newSummarizer())
+	if len(w.slots) > w.n {
+		w.slots[0] = nil
+		w.slots = w.slots[1:]
+	}
+}
+
+// summarizer returns a Summarizer for all the values in the window. For an
+// unbounded window, it is the live Summarizer that values are added to.
+func (w *window) summarizer() *summary.Summarizer {
+	if len(w.slots) == 1 {
+		return w.slots[0]
+	}
+	sr := w.newSummarizer()
+	for _, s := range w.slots {
+		if err := sr.Merge(s); err != nil {
+			panic(err) // the slots are all the same kind
+		}
+	}
+	return sr
+}
+
+// A streamPrinter prints successive summaries. For the table format, a
+// terminal is updated in place; otherwise, each summary is printed after a
+// timestamp line. The JSON format gives one object per summary with an
+// additional "time" field, and CSV and TSV give a single header row followed
+// by one row per summary with an additional time column.
+type streamPrinter struct {
+	w     io.Writer
+	tty   bool
+	opts  *summaryOptions
+	n     int // summaries printed so far
+	lines int // lines in the last summary printed to the terminal
+}
+
+func (sp *streamPrinter) print(sr *summary.Summarizer, t time.Time) error {
+	if sr.Count() == 0 && sp.n == 0 {
+		// Wait for the first values.
+		return nil
+	}
+	ts := t.Format(time.RFC3339)
+	var h *summary.Histogram
+	if sp.opts.printHist && sr.Count() > 0 {
+		var err error
+		h, err = sp.opts.hist.histogram(sr)
+		if err != nil {
+			return err
+		}
+	}
+	stats := sp.opts.stats(sr)
+
+	var buf bytes.Buffer
+	switch sp.opts.format {
+	case formatTable:
+		if sp.tty {
+			if sp.lines > 0 {
+				// Move up to the start of the last summary and clear
+				// everything below.
+				fmt.Fprintf(&buf, "\x1b[%dA\x1b[J", sp.lines)
+			}
+		} else {
+			if sp.n > 0 {
+				buf.WriteByte('\n')
+			}
+			fmt.Fprintf(&buf, "--- %s\n", ts)
+		}
+		start := buf.Len()
+		if err := writeSummary(&buf, formatTable, stats, h, sp.opts.unit); err != nil {
+			return err
+		}
+		sp.lines = bytes.Count(buf.Bytes()[start:], []byte("\n"))
+	case formatJSON:
+		stats = append([]stat{{"time", "time", ts}}, stats...)
+		if err := writeSummaryJSON(&buf, stats, h); err != nil {
+			return err
+		}
+	case formatCSV, formatTSV:
+		comma := ','
+		if sp.opts.format == formatTSV {
+			comma = '\t'
+		}
+		rows := [][]stat{append([]stat{{"time", "time", ts}}, stats...)}
+		write := writeDelimitedValues
+		if sp.n == 0 {
+			write = writeDelimited
+		}
+		if err := write(&buf, comma, rows); err != nil {
+			return err
+		}
+	case formatValues:
+		if err := writeRows(&buf, formatValues, [][]stat{stats}); err != nil {
+			return err
+		}
+	}
+	sp.n++
+	_, err := sp.w.Write(buf.Bytes())
+	return err
+}
+
+// isTerminal reports whether f appears to be a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
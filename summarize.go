@@ -1,281 +1,550 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"math"
-	"sort"
+	"os"
 	"strconv"
 	"strings"
-	"unicode/utf8"
 
-	"github.com/cespare/argf"
-	"github.com/cespare/stats/internal/b"
-	"github.com/cespare/tabular"
+	"github.com/cespare/stats/summary"
 )
 
 func summarize(args []string) {
 	fs := flag.NewFlagSet("summarize", flag.ExitOnError)
-	quantStr := fs.String("quantiles", "0.5,0.9,0.99", "Quantiles to record")
-	printHist := fs.Bool("hist", false, "Print a histogram")
-	histBuckets := fs.Int("buckets", 10, "How many buckets for the histogram")
+	sumOpts := addSummaryFlags(fs)
+	inOpts := addInputFlags(fs)
+	fs.StringVar(&inOpts.key, "groupby", "", "Summarize separately for each distinct value of this field")
+	sortBy := fs.String("sort", "", "With -groupby, order groups by this statistic (count, mean, p99, ...), largest first, instead of by key")
+	top := fs.Int("top", 0, "With -groupby, only print the first N groups")
+	approx := fs.Bool("approx", false, "Estimate quantiles using a t-digest, which uses bounded memory")
+	compression := fs.Float64("compression", 100, "With -approx, the t-digest compression (higher is more accurate)")
+	save := fs.String("save", "", "Also write the summarizer state to this file (see 'stats merge')")
+	interval := fs.Duration("interval", 0, "If nonzero, print the summary periodically while reading input")
+	window := fs.Duration("window", 0, "With -interval, only summarize values read during this recent period")
+	fs.BoolVar(&inOpts.columns, "columns", false, "Summarize every field separately, printing a column of statistics for each")
+	describe := fs.Bool("describe", false, "Like -columns, but for CSV input with a header (unless -delim is given), and also describe text columns")
 	fs.Parse(args)
 
-	if *histBuckets <= 1 {
-		log.Fatalf("%d is an invalid number of buckets", *histBuckets)
+	units, err := newUnitParser(inOpts.unit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := sumOpts.parse(units); err != nil {
+		log.Fatal(err)
 	}
 
-	var quants []float64
-	for _, qs := range strings.Split(*quantStr, ",") {
-		qs = strings.TrimSpace(qs)
-		f, err := strconv.ParseFloat(qs, 64)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if f <= 0 || f >= 1 {
-			log.Fatalf("quantile values must be in (0, 1); got %g", f)
+	if *approx && *compression < 20 {
+		log.Fatalf("compression must be at least 20; got %g", *compression)
+	}
+	if *approx && sumOpts.ci > 0 {
+		log.Fatal("-ci cannot be used with -approx (bootstrapping needs every value)")
+	}
+	if err := sumOpts.checkStats(*approx); err != nil {
+		log.Fatal(err)
+	}
+	newSummarizer := func() *summary.Summarizer {
+		sr := summary.New()
+		if *approx {
+			sr = summary.NewApprox(*compression)
 		}
-		quants = append(quants, f)
+		sr.SetQuantileMethod(sumOpts.method)
+		return sr
 	}
 
-	sr := newSummarizer(quants, *histBuckets)
-	var nonNumeric int64
-	argf.Init(flag.Args())
-	for argf.Scan() {
-		s := argf.String()
-		if s == "" {
-			continue
+	if *describe {
+		if inOpts.re != nil {
+			log.Fatal("-describe cannot be used with -re")
+		}
+		inOpts.columns = true
+		inOpts.header = true
+		if inOpts.delim == "" {
+			inOpts.csv = true
 		}
-		v, err := strconv.ParseFloat(s, 64)
+	}
+	if inOpts.key == "" && !inOpts.columns && inOpts.hasGroup("key") {
+		inOpts.key = "key"
+	}
+	if inOpts.key != "" {
+		if inOpts.field == "" && inOpts.re == nil {
+			log.Fatal("-groupby requires -field")
+		}
+		if sumOpts.printHist {
+			log.Fatal("-hist cannot be used with -groupby")
+		}
+		if *save != "" {
+			log.Fatal("-save cannot be used with -groupby")
+		}
+		if *interval > 0 {
+			log.Fatal("-interval cannot be used with -groupby")
+		}
+	}
+	if inOpts.columns {
+		switch {
+		case inOpts.key != "" || inOpts.field != "" || inOpts.weighted || inOpts.tokens:
+			log.Fatal("-columns cannot be used with -groupby, -field, -weighted, or -tokens")
+		case sumOpts.printHist || *save != "" || *interval > 0:
+			log.Fatal("-columns cannot be used with -hist, -save, or -interval")
+		}
+		summarizeColumns(inOpts, fs.Args(), newSummarizer, sumOpts, *describe)
+		return
+	}
+	if *interval < 0 || *window < 0 {
+		log.Fatal("-interval and -window must not be negative")
+	}
+	if *window > 0 && *interval == 0 {
+		log.Fatal("-window requires -interval")
+	}
+	if *interval > 0 && sumOpts.printHist && (sumOpts.format == formatCSV || sumOpts.format == formatTSV) {
+		log.Fatal("-hist cannot be used with -interval for csv or tsv output")
+	}
+
+	nr, err := newNumberReader(inOpts, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if inOpts.key != "" {
+		summarizeGroups(nr, newSummarizer, sumOpts, *sortBy, *top)
+		return
+	}
+	if *interval > 0 {
+		sr, err := streamSummary(os.Stdout, nr, newSummarizer, sumOpts, *interval, *window)
 		if err != nil {
-			nonNumeric++
-			continue
+			log.Fatal(err)
+		}
+		nr.warn()
+		if *save != "" {
+			if err := saveSummarizer(*save, sr); err != nil {
+				log.Fatal(err)
+			}
 		}
-		sr.add(v)
+		return
+	}
+	sr := newSummarizer()
+	for nr.Scan() {
+		sr.AddWeighted(nr.Value(), nr.Weight())
 	}
-	if err := argf.Error(); err != nil {
+	if err := nr.Err(); err != nil {
 		log.Fatal(err)
 	}
-	if nonNumeric > 0 {
-		log.Printf("warning: found %d non-numeric lines of input", nonNumeric)
+	nr.warn()
+	sumOpts.unit = nr.Unit()
+	if *save != "" {
+		if err := saveSummarizer(*save, sr); err != nil {
+			log.Fatal(err)
+		}
 	}
-	if sr.count == 0 {
+	if sr.Count() == 0 {
 		log.Println("no numbers given")
 		return
 	}
-	s := sr.summarize()
-	fmt.Println(s)
-	if *printHist {
-		fmt.Println(&s.hist)
+	if err := sumOpts.write(os.Stdout, sr); err != nil {
+		log.Fatal(err)
 	}
 }
 
-type summarizer struct {
-	summary
-	btree *b.Tree
+// summaryOptions are the flags that control how a summary is printed.
+type summaryOptions struct {
+	quantStr   string
+	methodStr  string
+	printHist  bool
+	moments    bool
+	robust     bool
+	trimMean   float64
+	belowStr   string
+	aboveStr   string
+	ci         float64
+	ciIters    int
+	ciSeed     int64
+	outlierStr string
+	outlierK   float64
+	trim       float64
+	winsorize  float64
+	hist       *histOptions
+	formatStr  string
+	statsStr   string
+	valueOnly  bool
+
+	// Set by parse.
+	quants   []float64
+	selected []string // the -stats names, or nil to print every statistic
+	method   summary.QuantileMethod
+	below    []float64
+	above    []float64
+	outliers *summary.OutlierMethod // nil if not flagging outliers
+	format   outputFormat
+
+	// unit is the unit of the values, which is known only once they have
+	// been read.
+	unit unit
 }
 
-func newSummarizer(quants []float64, numBuckets int) *summarizer {
-	btree := b.TreeNew(func(a, b float64) int {
-		if a < b {
-			return -1
-		}
-		if a == b {
-			return 0
-		}
-		return 1
-	})
-	sr := &summarizer{
-		btree: btree,
-		summary: summary{
-			quants: make([]quantile, len(quants)),
-			hist:   hist{buckets: make([]histBucket, numBuckets)},
-		},
-	}
-	sort.Float64s(quants)
-	for i, q := range quants {
-		sr.quants[i].q = q
-	}
-	return sr
+func addSummaryFlags(fs *flag.FlagSet) *summaryOptions {
+	var o summaryOptions
+	fs.StringVar(&o.quantStr, "quantiles", "0.5,0.9,0.99", "Quantiles to record")
+	fs.StringVar(&o.methodStr, "quantile-method", "nearest", quantileMethodUsage)
+	fs.BoolVar(&o.printHist, "hist", false, "Print a histogram")
+	fs.BoolVar(&o.moments, "moments", false, "Also print the variance, skewness, kurtosis, and related statistics")
+	fs.BoolVar(&o.robust, "robust", false, "Also print robust statistics (MAD, IQR, trimmed mean), the geometric and harmonic means, and the mode")
+	fs.Float64Var(&o.trimMean, "trimmed-mean", 0.1, "With -robust, the fraction of the lowest and of the highest values to leave out of the trimmed mean")
+	fs.StringVar(&o.belowStr, "below", "", "Comma-separated thresholds; print the count and fraction of values at or below each")
+	fs.StringVar(&o.aboveStr, "above", "", "Comma-separated thresholds; print the count and fraction of values above each")
+	fs.Float64Var(&o.ci, "ci", 0, "If nonzero, print confidence intervals at this level (such as 0.95) for the mean, median, and quantiles")
+	fs.IntVar(&o.ciIters, "bootstrap-iters", 1000, "With -ci, the number of bootstrap resamples")
+	fs.Int64Var(&o.ciSeed, "seed", 1, "With -ci, the seed for drawing bootstrap resamples")
+	fs.StringVar(&o.outlierStr, "outliers", "", outlierMethodUsage+"; count the values flagged as outliers by this method")
+	fs.Float64Var(&o.outlierK, "outlier-threshold", 0, outlierThresholdUsage)
+	fs.Float64Var(&o.trim, "trim", 0, "Compute every statistic without this fraction of the lowest and of the highest values")
+	fs.Float64Var(&o.winsorize, "winsorize", 0, "Compute every statistic with this fraction of the lowest and of the highest values "+
+		"replaced by the nearest remaining value")
+	o.hist = addHistFlags(fs)
+	fs.StringVar(&o.formatStr, "format", "table", "Output format: table, json, csv, or tsv")
+	fs.StringVar(&o.statsStr, "stats", "", "Comma-separated statistics to print, in order, by their names in the json output "+
+		"(such as count,mean,p99.9); quantiles named here replace -quantiles")
+	fs.BoolVar(&o.valueOnly, "value-only", false, "Print only the values of the statistics, one per line "+
+		"(or, for several summaries, one line of space-separated values for each)")
+	return &o
 }
 
-func (sr *summarizer) add(v float64) {
-	if sr.count == 0 || v < sr.min {
-		sr.min = v
+// parse validates the flags after they have been parsed. Thresholds and
+// histogram bounds are parsed in the unit of the input, as given by units.
+func (o *summaryOptions) parse(units *unitParser) error {
+	var err error
+	o.format, err = parseFormat(o.formatStr)
+	if err != nil {
+		return err
 	}
-	if sr.count == 0 || v > sr.max {
-		sr.max = v
+
+	if err := o.hist.parse(units); err != nil {
+		return err
 	}
-	sr.btree.Put(v, func(c int64, _ bool) (int64, bool) { return c + 1, true })
-	sr.count++
-}
 
-func (sr *summarizer) summarize() *summary {
-	it, err := sr.btree.SeekFirst()
+	o.method, err = parseQuantileMethod(o.methodStr)
 	if err != nil {
-		panic(err)
-	}
-	for i, q := range sr.quants {
-		sr.quants[i].i = round(q.q * float64(sr.count-1))
-	}
-	var (
-		qi   int
-		bi   int
-		i    int64
-		rnge = sr.max - sr.min
-	)
-	// TODO: If the range is large, expand the bucketsize and start/end a
-	// little bit to obtain integer boundaries.
-	sr.bucketSize = rnge / float64(len(sr.buckets))
-	sr.buckets[0].start = sr.min
-	bucketLimit := sr.min + sr.bucketSize
-	for {
-		v, c, err := it.Next()
-		if err == io.EOF {
-			break
+		return err
+	}
+
+	if o.belowStr != "" {
+		if o.below, err = parseFloats(o.belowStr, units); err != nil {
+			return err
+		}
+	}
+	if o.aboveStr != "" {
+		if o.above, err = parseFloats(o.aboveStr, units); err != nil {
+			return err
 		}
+	}
+
+	if o.ci != 0 && !(o.ci > 0 && o.ci < 1) {
+		return fmt.Errorf("-ci must be in (0, 1); got %g", o.ci)
+	}
+	if o.ciIters < 1 {
+		return fmt.Errorf("-bootstrap-iters must be positive; got %d", o.ciIters)
+	}
+
+	if o.outlierStr != "" {
+		m, err := parseOutlierMethod(o.outlierStr)
 		if err != nil {
-			panic(err)
+			return err
 		}
-		for j := 0; j < int(c); j++ {
-			sr.sum += v
-			sr.sumSquares += v * v
-			for qi < len(sr.quants) && i == sr.quants[qi].i {
-				sr.quants[qi].v = v
-				qi++
-			}
-			i++
+		o.outliers = &m
+		if o.outlierK == 0 {
+			o.outlierK = m.DefaultThreshold()
 		}
-		for v >= bucketLimit && bi < len(sr.buckets)-1 {
-			bi++
-			sr.buckets[bi].start = bucketLimit
-			bucketLimit = sr.min + float64(bi+1)*sr.bucketSize
+	}
+	if o.outlierK < 0 {
+		return fmt.Errorf("-outlier-threshold must not be negative; got %g", o.outlierK)
+	}
+	for _, f := range []struct {
+		name string
+		p    float64
+	}{{"trim", o.trim}, {"winsorize", o.winsorize}} {
+		if !(f.p >= 0 && f.p < 0.5) {
+			return fmt.Errorf("-%s must be in [0, 0.5); got %g", f.name, f.p)
 		}
-		sr.buckets[bi].count += c
 	}
-	return &sr.summary
-}
+	if !(o.trimMean >= 0 && o.trimMean < 0.5) {
+		return fmt.Errorf("-trimmed-mean must be in [0, 0.5); got %g", o.trimMean)
+	}
+	if o.trim > 0 && o.winsorize > 0 {
+		return errors.New("-trim and -winsorize cannot be used together")
+	}
 
-type summary struct {
-	count      int64
-	min        float64
-	max        float64
-	sum        float64
-	sumSquares float64
-	quants     []quantile
-	hist
-}
+	if o.valueOnly {
+		if o.formatStr != "table" {
+			return errors.New("-value-only cannot be used with -format")
+		}
+		if o.printHist {
+			return errors.New("-value-only cannot be used with -hist")
+		}
+		o.format = formatValues
+	}
 
-type quantile struct {
-	q float64 // e.g., 0.9 for 90th percentile
-	i int64   // index of quantile value
-	v float64 // quantile value
+	if o.statsStr != "" {
+		return o.parseStats()
+	}
+	o.quants, err = parseQuantiles(o.quantStr)
+	return err
 }
 
-type histBucket struct {
-	start float64
-	count int64
-}
+// parseStats parses the -stats list. The quantiles become those named in
+// the list (including as part of names such as p99_ci_lo), and the moments
+// and robust statistics are enabled if any of them are named. The other
+// optional statistics must be enabled by their own flags.
+func (o *summaryOptions) parseStats() error {
+	var selected []string
+	o.quants = nil
+	for _, name := range strings.Split(o.statsStr, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if q, suffix, ok := parseQuantileName(name); ok {
+			if q <= 0 || q >= 1 {
+				return fmt.Errorf("quantile values must be in (0, 1); got %g in %q", q, name)
+			}
+			if !containsFloat(o.quants, q) {
+				o.quants = append(o.quants, q)
+			}
+			name = quantileName(q) + suffix
+		}
+		selected = append(selected, name)
+	}
+	if len(selected) == 0 {
+		return errors.New("-stats must name at least one statistic")
+	}
 
-type hist struct {
-	bucketSize float64
-	buckets    []histBucket
+	empty := summary.New()
+	moments := summaryStats(empty, nil, true, unitNone)
+	robust := robustStats(empty, o.trimMean, unitNone)
+	for _, name := range selected {
+		o.moments = o.moments || hasStat(moments, name)
+		o.robust = o.robust || hasStat(robust, name)
+	}
+	o.selected = selected
+	return nil
 }
 
-func (s *summary) String() string {
-	tb := tabular.New(tabular.Options{Padding: 4, PadChar: ' '})
+// checkStats checks that the -stats names are all statistics of a summary,
+// which is approximate if approx is set.
+func (o *summaryOptions) checkStats(approx bool) error {
+	if o.selected == nil {
+		return nil
+	}
+	exact := summary.New()
+	known := append(summaryStats(exact, nil, true, unitNone), robustStats(exact, o.trimMean, unitNone)...)
+	known = append(known, o.allStats(exact, exact)...)
+	// An approximate Summarizer also reports the rank error of each
+	// quantile.
+	approxOnly := summaryStats(summary.NewApprox(20), o.quants, false, unitNone)
+	for _, name := range o.selected {
+		if hasStat(known, name) {
+			continue
+		}
+		if hasStat(approxOnly, name) {
+			if approx {
+				continue
+			}
+			return fmt.Errorf("statistic %q is only available for approximate summaries (-approx)", name)
+		}
+		var names []string
+		seen := make(map[string]bool)
+		for _, st := range known {
+			if !seen[st.name] {
+				seen[st.name] = true
+				names = append(names, st.name)
+			}
+		}
+		return fmt.Errorf("unknown statistic %q (must be a quantile such as p99.9 or one of %s; "+
+			"-below, -above, -outliers, and -ci add more)", name, strings.Join(names, ", "))
+	}
+	return nil
+}
 
-	n := float64(s.count)
-	mean := s.sum / n
-	stdev := math.Sqrt(n*s.sumSquares-(s.sum*s.sum)) / n
+// parseQuantileName parses a statistic name that starts with a quantile
+// given as a percentile, such as p99.9 or p50_ci_lo, returning the quantile
+// and the rest of the name.
+func parseQuantileName(name string) (q float64, suffix string, ok bool) {
+	if !strings.HasPrefix(name, "p") {
+		return 0, "", false
+	}
+	pct := name[1:]
+	if i := strings.IndexByte(pct, '_'); i >= 0 {
+		pct, suffix = pct[:i], pct[i:]
+	}
+	p, err := strconv.ParseFloat(pct, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	q = math.Round(p*1e9) / 1e11 // avoid 0.9990000000000001 for p99.9
+	return q, suffix, true
+}
 
-	tb.AddRow("count", s.count)
-	tb.AddRow("min", s.min)
-	tb.AddRow("max", s.max)
-	tb.AddRow("mean", mean)
-	tb.AddRow("std. dev.", stdev)
-	for _, q := range s.quants {
-		tb.AddRow(fmt.Sprintf("quantile %g", q.q), q.v)
+func containsFloat(fs []float64, f float64) bool {
+	for _, g := range fs {
+		if g == f {
+			return true
+		}
 	}
+	return false
+}
 
-	var buf bytes.Buffer
-	tb.WriteTo(&buf)
-	b := buf.Bytes()
-	return string(b[:len(b)-1]) // drop the \n
+func hasStat(stats []stat, name string) bool {
+	for _, st := range stats {
+		if st.name == name {
+			return true
+		}
+	}
+	return false
 }
 
-const histBlocks = 70
-
-func (h *hist) String() string {
-	labels := make([]string, len(h.buckets))
-	labelSpaceBefore := 0
-	labelSpaceAfter := 0
-	var maxCount, sum float64
-	for i, b := range h.buckets {
-		sum += float64(b.count)
-		s := "<"
-		if i == len(h.buckets)-1 {
-			s = "≤"
+// selectStats picks the named statistics out of stats, in the order given.
+// A name that isn't present gives a statistic with no value.
+func selectStats(stats []stat, names []string) []stat {
+	selected := make([]stat, len(names))
+	for i, name := range names {
+		selected[i] = stat{name, name, nil}
+		for _, st := range stats {
+			if st.name == name {
+				selected[i] = st
+				break
+			}
 		}
-		label := fmt.Sprintf("%.3g ≤ x %s %.3g", b.start, s, b.start+h.bucketSize)
-		xPos := runeIndex(label, 'x')
-		if xPos > labelSpaceBefore {
-			labelSpaceBefore = xPos
+	}
+	return selected
+}
+
+// parseFloats parses a comma-separated list of numbers in the unit given by
+// units, as for parseValue.
+func parseFloats(s string, units *unitParser) ([]float64, error) {
+	var fs []float64
+	for _, f := range strings.Split(s, ",") {
+		v, err := parseValue(f, units)
+		if err != nil {
+			return nil, err
 		}
-		if after := utf8.RuneCountInString(label) - xPos - 1; after > labelSpaceAfter {
-			labelSpaceAfter = after
+		fs = append(fs, v)
+	}
+	return fs, nil
+}
+
+// parseQuantiles parses a comma-separated list of quantiles.
+func parseQuantiles(s string) ([]float64, error) {
+	var quants []float64
+	for _, qs := range strings.Split(s, ",") {
+		qs = strings.TrimSpace(qs)
+		f, err := strconv.ParseFloat(qs, 64)
+		if err != nil {
+			return nil, err
 		}
-		labels[i] = label
-		if f := float64(b.count); f > maxCount {
-			maxCount = f
+		if f <= 0 || f >= 1 {
+			return nil, fmt.Errorf("quantile values must be in (0, 1); got %g", f)
 		}
+		quants = append(quants, f)
 	}
+	return quants, nil
+}
+
+const quantileMethodUsage = "How to compute exact quantiles: nearest (the value of the nearest rank), " +
+	"1-9 (the Hyndman-Fan sample quantile types), or linear (type 7, as in R, NumPy, and Excel)"
 
-	var buf bytes.Buffer
-	for i, b := range h.buckets {
-		xPos := runeIndex(labels[i], 'x')
-		before := labelSpaceBefore - xPos
-		after := labelSpaceAfter - utf8.RuneCountInString(labels[i]) + xPos + 1
-		fmt.Fprintf(&buf, " %*s%s%*s │", before, "", labels[i], after, "")
-		fmt.Fprint(&buf, bar((float64(b.count)/float64(maxCount))*histBlocks))
-		fmt.Fprintf(&buf, " %d (%.3f%%)\n", b.count, 100*float64(b.count)/sum)
+// parseQuantileMethod parses the value of a -quantile-method flag.
+func parseQuantileMethod(s string) (summary.QuantileMethod, error) {
+	switch s {
+	case "nearest":
+		return summary.Nearest, nil
+	case "linear":
+		return summary.Linear, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "type"))
+	if err != nil || n < 1 || n > 9 {
+		return 0, fmt.Errorf("unknown quantile method %q (must be nearest, linear, or 1-9)", s)
 	}
-	b := buf.Bytes()
-	return string(b[:len(b)-1]) // drop the \n
+	return summary.QuantileMethod(n), nil
 }
 
-func runeIndex(s string, r rune) int {
-	for i, r2 := range []rune(s) {
-		if r2 == r {
-			return i
+func (o *summaryOptions) write(w io.Writer, sr *summary.Summarizer) error {
+	data := o.cut(sr)
+	var h *summary.Histogram
+	if o.printHist {
+		var err error
+		h, err = o.hist.histogram(data)
+		if err != nil {
+			return err
 		}
 	}
-	return -1
+	return writeSummary(w, o.format, o.statsOf(sr, data), h, o.unit)
 }
 
-var barEighths = [9]rune{
-	' ', // empty
-	'▏',
-	'▎',
-	'▍',
-	'▌',
-	'▋',
-	'▊',
-	'▉',
-	'█', // full
+func (o *summaryOptions) stats(sr *summary.Summarizer) []stat {
+	return o.statsOf(sr, o.cut(sr))
 }
 
-func bar(n float64) string {
-	eighths := int(round(n * 8))
-	full := eighths / 8
-	rem := eighths % 8
-	return strings.Repeat(string(barEighths[8]), full) + string(barEighths[rem])
+// statsOf gives the statistics of data, which is sr after any trimming or
+// winsorizing, or with -stats, those it names. Outliers are counted in sr.
+func (o *summaryOptions) statsOf(sr, data *summary.Summarizer) []stat {
+	stats := o.allStats(sr, data)
+	if o.selected != nil {
+		stats = selectStats(stats, o.selected)
+	}
+	return stats
 }
 
-// assumes positive v
-func round(v float64) int64 {
-	return int64(v + 0.5)
+// allStats is like statsOf, but ignores -stats.
+func (o *summaryOptions) allStats(sr, data *summary.Summarizer) []stat {
+	stats := summaryStats(data, o.quants, o.moments, o.unit)
+	if o.robust {
+		stats = append(stats, robustStats(data, o.trimMean, o.unit)...)
+	}
+	stats = append(stats, thresholdStats(data, o.below, o.above, o.unit)...)
+	if o.outliers != nil {
+		stats = append(stats, outlierStats(sr, *o.outliers, o.outlierK, o.unit)...)
+	}
+	if o.ci > 0 {
+		stats = append(stats, ciStats(data, o.quants, o.ci, o.ciIters, o.ciSeed, o.unit)...)
+	}
+	return stats
+}
+
+// cut applies -trim or -winsorize to sr.
+func (o *summaryOptions) cut(sr *summary.Summarizer) *summary.Summarizer {
+	switch {
+	case o.trim > 0:
+		return sr.Trimmed(o.trim)
+	case o.winsorize > 0:
+		return sr.Winsorized(o.winsorize)
+	}
+	return sr
+}
+
+func summarizeGroups(nr *numberReader, newSummarizer func() *summary.Summarizer, opts *summaryOptions, sortBy string, top int) {
+	groups := make(map[string]*summary.Summarizer)
+	for nr.Scan() {
+		sr, ok := groups[nr.Key()]
+		if !ok {
+			sr = newSummarizer()
+			groups[nr.Key()] = sr
+		}
+		sr.AddWeighted(nr.Value(), nr.Weight())
+	}
+	if err := nr.Err(); err != nil {
+		log.Fatal(err)
+	}
+	nr.warn()
+	opts.unit = nr.Unit()
+	if len(groups) == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	rows, err := groupRows(groups, opts.stats, sortBy, top)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeRows(os.Stdout, opts.format, rows); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestCheckStats(t *testing.T) {
+	for _, tt := range []struct {
+		stats    string
+		exactOK  bool
+		approxOK bool
+	}{
+		{"count,p99.9,mad", true, true},
+		{"p99,p99_rank_error", false, true},
+		{"bogus", false, false},
+	} {
+		fs := flag.NewFlagSet("summarize", flag.ContinueOnError)
+		o := addSummaryFlags(fs)
+		if err := fs.Parse([]string{"-stats", tt.stats}); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.parse(&unitParser{}); err != nil {
+			t.Fatalf("-stats %s: %s", tt.stats, err)
+		}
+		for _, approx := range []bool{false, true} {
+			want := tt.exactOK
+			if approx {
+				want = tt.approxOK
+			}
+			if err := o.checkStats(approx); (err == nil) != want {
+				t.Errorf("-stats %s, approx %t: got error %v; want ok = %t", tt.stats, approx, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package summary
+
+import "math"
+
+// A Box holds the values drawn by a box plot.
+type Box struct {
+	Q1, Median, Q3 float64
+	// WhiskerLo and WhiskerHi are the lowest and highest values that are
+	// not outliers, or Q1 and Q3 if those are more extreme.
+	WhiskerLo, WhiskerHi float64
+	// Outliers are the distinct values outside the IQR fences, in
+	// increasing order.
+	Outliers []float64
+}
+
+// BoxPlot gives the quartiles of s along with the whiskers and outliers of a
+// Tukey box plot whose fences are k interquartile ranges beyond the
+// quartiles (see OutlierFences). The values are NaN if s is empty. For an
+// approximate Summarizer, the results are estimates and the outliers are
+// t-digest centroids.
+func (s *Summarizer) BoxPlot(k float64) Box {
+	nan := math.NaN()
+	if s.count == 0 {
+		return Box{Q1: nan, Median: nan, Q3: nan, WhiskerLo: nan, WhiskerHi: nan}
+	}
+	qs := s.Quantiles([]float64{0.25, 0.5, 0.75})
+	b := Box{Q1: qs[0], Median: qs[1], Q3: qs[2], WhiskerLo: nan, WhiskerHi: nan}
+	lo, hi := s.OutlierFences(IQR, k)
+	s.walk(func(v, w float64) bool {
+		if v < lo || v > hi {
+			b.Outliers = append(b.Outliers, v)
+			return true
+		}
+		if math.IsNaN(b.WhiskerLo) {
+			b.WhiskerLo = v
+		}
+		b.WhiskerHi = v
+		return true
+	})
+	// The whiskers reach at least to the quartiles, even if no values lie
+	// between them and the fences (as with a small k and interpolated
+	// quartiles).
+	if !(b.WhiskerLo <= b.Q1) {
+		b.WhiskerLo = b.Q1
+	}
+	if !(b.WhiskerHi >= b.Q3) {
+		b.WhiskerHi = b.Q3
+	}
+	return b
+}
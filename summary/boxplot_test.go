@@ -0,0 +1,43 @@
+package summary
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBoxPlot(t *testing.T) {
+	var vs []float64
+	for i := 1; i <= 10; i++ {
+		vs = append(vs, float64(i))
+	}
+	// The quartiles (nearest rank) are 3 and 9, so the fences are -6
+	// and 18.
+	s := newTestSummarizer(append(vs, -10, 20, 20)...)
+	got := s.BoxPlot(1.5)
+	want := Box{Q1: 3, Median: 6, Q3: 9, WhiskerLo: 1, WhiskerHi: 10, Outliers: []float64{-10, 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BoxPlot(1.5): got %+v; want %+v", got, want)
+	}
+
+	got = newTestSummarizer(vs...).BoxPlot(1.5)
+	want = Box{Q1: 3, Median: 6, Q3: 8, WhiskerLo: 1, WhiskerHi: 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BoxPlot(1.5) without outliers: got %+v; want %+v", got, want)
+	}
+
+	// With k = 0 and interpolated quartiles, no value lies within the
+	// fences.
+	s = newTestSummarizer(1, 2)
+	s.SetQuantileMethod(Linear)
+	got = s.BoxPlot(0)
+	want = Box{Q1: 1.25, Median: 1.5, Q3: 1.75, WhiskerLo: 1.25, WhiskerHi: 1.75, Outliers: []float64{1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BoxPlot(0) with no values inside the fences: got %+v; want %+v", got, want)
+	}
+
+	got = New().BoxPlot(1.5)
+	if !math.IsNaN(got.Median) || !math.IsNaN(got.WhiskerLo) || got.Outliers != nil {
+		t.Errorf("BoxPlot of empty Summarizer: got %+v; want NaNs", got)
+	}
+}
@@ -0,0 +1,51 @@
+package summary
+
+import "math"
+
+// Rank returns the total weight of the values less than or equal to x (for
+// unweighted values, the number of them). It is an estimate for an
+// approximate Summarizer. Rank returns NaN if x is NaN.
+func (s *Summarizer) Rank(x float64) float64 {
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+	if s.digest != nil {
+		return s.digest.rank(x, s.min, s.max)
+	}
+	return s.tree.Rank(x)
+}
+
+// CDF returns the fraction of the total weight of the values that are less
+// than or equal to x: the empirical cumulative distribution function at x.
+// It is an estimate for an approximate Summarizer. CDF returns NaN if s is
+// empty or x is NaN.
+func (s *Summarizer) CDF(x float64) float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.Rank(x) / s.weight
+}
+
+// A CDFPoint is a step of an empirical cumulative distribution function.
+type CDFPoint struct {
+	Value      float64
+	Weight     float64 // the total weight of the values equal to Value
+	Cumulative float64 // the total weight of the values ≤ Value
+}
+
+// ECDF returns the empirical cumulative distribution function of the values
+// as a point for each distinct value, in increasing order. For an
+// approximate Summarizer, it gives a point for each t-digest centroid
+// instead.
+func (s *Summarizer) ECDF() []CDFPoint {
+	var (
+		points []CDFPoint
+		cum    float64
+	)
+	s.walk(func(v, w float64) bool {
+		cum += w
+		points = append(points, CDFPoint{Value: v, Weight: w, Cumulative: cum})
+		return true
+	})
+	return points
+}
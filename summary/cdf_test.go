@@ -0,0 +1,79 @@
+package summary
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	s := newTestSummarizer(2, 7, 1, 3, 2)
+	for _, tt := range []struct {
+		x    float64
+		rank float64
+	}{
+		{0, 0},
+		{1, 1},
+		{1.5, 1},
+		{2, 3},
+		{3, 4},
+		{6.9, 4},
+		{7, 5},
+		{math.Inf(1), 5},
+	} {
+		if got := s.Rank(tt.x); got != tt.rank {
+			t.Errorf("Rank(%g): got %g; want %g", tt.x, got, tt.rank)
+		}
+		if got, want := s.CDF(tt.x), tt.rank/5; got != want {
+			t.Errorf("CDF(%g): got %g; want %g", tt.x, got, want)
+		}
+	}
+	if got := s.Rank(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("Rank(NaN): got %g; want NaN", got)
+	}
+	if got := New().CDF(1); !math.IsNaN(got) {
+		t.Errorf("CDF of empty Summarizer: got %g; want NaN", got)
+	}
+}
+
+func TestRankApprox(t *testing.T) {
+	s := NewApprox(100)
+	for i := 0; i < 10000; i++ {
+		s.Add(float64(i))
+	}
+	if got := s.Rank(-1); got != 0 {
+		t.Errorf("Rank(-1): got %g; want 0", got)
+	}
+	if got := s.Rank(9999); got != 10000 {
+		t.Errorf("Rank(9999): got %g; want 10000", got)
+	}
+	prev := 0.0
+	for x := 0.0; x < 10000; x += 250 {
+		got := s.CDF(x)
+		if got < prev {
+			t.Errorf("CDF(%g) = %g is less than the CDF of a smaller value (%g)", x, got, prev)
+		}
+		prev = got
+		if want := (x + 1) / 10000; math.Abs(got-want) > 0.01 {
+			t.Errorf("CDF(%g): got %g; want about %g", x, got, want)
+		}
+	}
+	for _, q := range []float64{0.01, 0.5, 0.99} {
+		if got := s.CDF(s.Quantile(q)); math.Abs(got-q) > 1e-9 {
+			t.Errorf("CDF(Quantile(%g)): got %g", q, got)
+		}
+	}
+}
+
+func TestECDF(t *testing.T) {
+	s := newTestSummarizer(2, 7, 1, 3, 2)
+	want := []CDFPoint{
+		{Value: 1, Weight: 1, Cumulative: 1},
+		{Value: 2, Weight: 2, Cumulative: 3},
+		{Value: 3, Weight: 1, Cumulative: 4},
+		{Value: 7, Weight: 1, Cumulative: 5},
+	}
+	if got := s.ECDF(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ECDF: got %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,303 @@
+package summary
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/cespare/stats/internal/dist"
+)
+
+// A Correlation is a correlation coefficient between paired values along
+// with the two-sided p-value of a test of the null hypothesis that the
+// values are uncorrelated.
+type Correlation struct {
+	Coefficient float64
+	P           float64
+}
+
+// ErrConstant is returned when one of the variables of paired data takes
+// only a single value, so that the statistic is undefined.
+var ErrConstant = errors.New("summary: variable is constant")
+
+// checkPairs panics if xs and ys differ in length and returns ErrSampleSize
+// if there are fewer than three pairs.
+func checkPairs(xs, ys []float64) error {
+	if len(xs) != len(ys) {
+		panic("summary: paired slices have different lengths")
+	}
+	if len(xs) < 3 {
+		return ErrSampleSize
+	}
+	return nil
+}
+
+// Pearson computes Pearson's product-moment correlation coefficient of the
+// pairs (xs[i], ys[i]), which measures how close they are to lying on a
+// line. The p-value comes from Student's t distribution with n-2 degrees of
+// freedom. Pearson panics if xs and ys have different lengths.
+func Pearson(xs, ys []float64) (Correlation, error) {
+	if err := checkPairs(xs, ys); err != nil {
+		return Correlation{}, err
+	}
+	_, _, sxx, syy, sxy := sumsOfSquares(xs, ys)
+	if sxx == 0 || syy == 0 {
+		return Correlation{}, ErrConstant
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	r = math.Max(-1, math.Min(1, r))
+	return Correlation{Coefficient: r, P: correlationP(r, len(xs))}, nil
+}
+
+// correlationP gives the p-value of a correlation coefficient r of n pairs
+// using the t statistic r·√((n-2)/(1-r²)).
+func correlationP(r float64, n int) float64 {
+	if math.Abs(r) == 1 {
+		return 0
+	}
+	df := float64(n - 2)
+	t := r * math.Sqrt(df/(1-r*r))
+	return 2 * dist.StudentTCDF(-math.Abs(t), df)
+}
+
+// sumsOfSquares returns the means of xs and ys along with the sums of
+// squared deviations from them and the sum of the products of the
+// deviations.
+func sumsOfSquares(xs, ys []float64) (mx, my, sxx, syy, sxy float64) {
+	n := float64(len(xs))
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= n
+	my /= n
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	return mx, my, sxx, syy, sxy
+}
+
+// Spearman computes Spearman's rank correlation coefficient of the pairs
+// (xs[i], ys[i]): Pearson's correlation of their ranks, with tied values
+// given the average of their ranks. It measures how well the relationship
+// between the values is described by a monotonic function. The p-value uses
+// the same t approximation as Pearson. Spearman panics if xs and ys have
+// different lengths.
+func Spearman(xs, ys []float64) (Correlation, error) {
+	if err := checkPairs(xs, ys); err != nil {
+		return Correlation{}, err
+	}
+	return Pearson(ranks(xs), ranks(ys))
+}
+
+// ranks returns the 1-based rank of each value in vs, giving tied values
+// the average of their ranks.
+func ranks(vs []float64) []float64 {
+	idx := make([]int, len(vs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return vs[idx[i]] < vs[idx[j]] })
+	rs := make([]float64, len(vs))
+	for i := 0; i < len(idx); {
+		j := i + 1
+		for j < len(idx) && vs[idx[j]] == vs[idx[i]] {
+			j++
+		}
+		r := float64(i+j+1) / 2 // the average of ranks i+1 through j
+		for k := i; k < j; k++ {
+			rs[idx[k]] = r
+		}
+		i = j
+	}
+	return rs
+}
+
+// Kendall computes Kendall's rank correlation coefficient τ-b of the pairs
+// (xs[i], ys[i]): the difference between the numbers of concordant and
+// discordant pairs of pairs, normalized to account for ties. The p-value
+// uses the normal approximation with the variance corrected for ties. It
+// takes O(n log n) time using Knight's algorithm. Kendall panics if xs and
+// ys have different lengths.
+func Kendall(xs, ys []float64) (Correlation, error) {
+	if err := checkPairs(xs, ys); err != nil {
+		return Correlation{}, err
+	}
+	n := len(xs)
+	type pair struct{ x, y float64 }
+	ps := make([]pair, n)
+	for i := range xs {
+		ps[i] = pair{xs[i], ys[i]}
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].x != ps[j].x {
+			return ps[i].x < ps[j].x
+		}
+		return ps[i].y < ps[j].y
+	})
+
+	// Count the pairs tied in x, and in both x and y.
+	var xTies tieCounts
+	var bothTied float64
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && ps[j].x == ps[i].x {
+			j++
+		}
+		xTies.add(j - i)
+		for k := i; k < j; {
+			l := k + 1
+			for l < j && ps[l].y == ps[k].y {
+				l++
+			}
+			bothTied += pairsOf(l - k)
+			k = l
+		}
+		i = j
+	}
+
+	// Sorting by y, the number of discordant pairs is the number of swaps
+	// a merge sort makes.
+	ys2 := make([]float64, n)
+	for i, p := range ps {
+		ys2[i] = p.y
+	}
+	discordant := mergeSortSwaps(ys2, make([]float64, n))
+	var yTies tieCounts
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && ys2[j] == ys2[i] {
+			j++
+		}
+		yTies.add(j - i)
+		i = j
+	}
+
+	total := pairsOf(n)
+	if xTies.pairs == total || yTies.pairs == total {
+		return Correlation{}, ErrConstant
+	}
+	s := total - xTies.pairs - yTies.pairs + bothTied - 2*discordant
+	tau := s / math.Sqrt(total-xTies.pairs) / math.Sqrt(total-yTies.pairs)
+
+	nf := float64(n)
+	m := nf * (nf - 1)
+	v := (m*(2*nf+5)-xTies.v1-yTies.v1)/18 +
+		2*xTies.pairs*yTies.pairs/m +
+		xTies.v0*yTies.v0/(9*m*(nf-2))
+	z := s / math.Sqrt(v)
+	return Correlation{Coefficient: tau, P: 2 * dist.NormalCDF(-math.Abs(z))}, nil
+}
+
+// pairsOf returns the number of pairs among n items.
+func pairsOf(n int) float64 {
+	return float64(n) * float64(n-1) / 2
+}
+
+// tieCounts accumulates the sums over groups of t tied values that are
+// needed by Kendall's τ-b and its variance.
+type tieCounts struct {
+	pairs float64 // Σ t(t-1)/2
+	v0    float64 // Σ t(t-1)(t-2)
+	v1    float64 // Σ t(t-1)(2t+5)
+}
+
+func (c *tieCounts) add(t int) {
+	if t < 2 {
+		return
+	}
+	tf := float64(t)
+	c.pairs += pairsOf(t)
+	c.v0 += tf * (tf - 1) * (tf - 2)
+	c.v1 += tf * (tf - 1) * (2*tf + 5)
+}
+
+// mergeSortSwaps sorts vs using tmp (of the same length) as scratch space
+// and returns the number of inversions: the pairs i < j with vs[i] > vs[j].
+func mergeSortSwaps(vs, tmp []float64) float64 {
+	if len(vs) < 2 {
+		return 0
+	}
+	mid := len(vs) / 2
+	swaps := mergeSortSwaps(vs[:mid], tmp[:mid]) + mergeSortSwaps(vs[mid:], tmp[mid:])
+	copy(tmp, vs)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(vs) {
+		if tmp[j] < tmp[i] {
+			vs[k] = tmp[j]
+			j++
+			swaps += float64(mid - i)
+		} else {
+			vs[k] = tmp[i]
+			i++
+		}
+		k++
+	}
+	k += copy(vs[k:], tmp[i:mid])
+	copy(vs[k:], tmp[j:])
+	return swaps
+}
+
+// A LinearFit is an ordinary least squares fit of the line
+// y = Intercept + Slope·x to paired data.
+type LinearFit struct {
+	N         int
+	Slope     float64
+	Intercept float64
+	R2        float64 // the coefficient of determination
+
+	// The standard errors of the coefficients and of the residuals.
+	SlopeErr     float64
+	InterceptErr float64
+	ResidualErr  float64
+
+	// P is the two-sided p-value of a t-test of the null hypothesis that
+	// the slope is 0.
+	P float64
+}
+
+// FitLine fits a line to the pairs (xs[i], ys[i]) by ordinary least
+// squares. It panics if xs and ys have different lengths.
+func FitLine(xs, ys []float64) (LinearFit, error) {
+	if err := checkPairs(xs, ys); err != nil {
+		return LinearFit{}, err
+	}
+	mx, my, sxx, syy, sxy := sumsOfSquares(xs, ys)
+	if sxx == 0 {
+		return LinearFit{}, ErrConstant
+	}
+	f := LinearFit{N: len(xs)}
+	f.Slope = sxy / sxx
+	f.Intercept = my - f.Slope*mx
+	var sse float64
+	for i := range xs {
+		r := ys[i] - f.Predict(xs[i])
+		sse += r * r
+	}
+	f.R2 = 1 - sse/syy
+	if syy == 0 {
+		f.R2 = 1 // a horizontal line fits perfectly
+	}
+	n := float64(len(xs))
+	f.ResidualErr = math.Sqrt(sse / (n - 2))
+	f.SlopeErr = f.ResidualErr / math.Sqrt(sxx)
+	f.InterceptErr = f.ResidualErr * math.Sqrt(1/n+mx*mx/sxx)
+	if f.SlopeErr == 0 {
+		f.P = 0
+		if f.Slope == 0 {
+			f.P = 1
+		}
+	} else {
+		t := f.Slope / f.SlopeErr
+		f.P = 2 * dist.StudentTCDF(-math.Abs(t), n-2)
+	}
+	return f, nil
+}
+
+// Predict returns the value of the fitted line at x.
+func (f LinearFit) Predict(x float64) float64 {
+	return f.Intercept + f.Slope*x
+}
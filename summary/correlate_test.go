@@ -0,0 +1,185 @@
+package summary
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// An example from Hollander and Wolfe, also used in R's cor.test
+// documentation.
+var (
+	corrX = []float64{44.4, 45.9, 41.9, 53.3, 44.7, 44.1, 50.7, 45.2, 60.1}
+	corrY = []float64{2.6, 3.1, 2.5, 5.0, 3.6, 4.0, 5.2, 2.8, 3.8}
+)
+
+// Data with ties in x, in y, and in both.
+var (
+	tiedX = []float64{1, 2, 2, 3, 3, 3, 4, 5, 5, 6}
+	tiedY = []float64{2, 1, 3, 3, 3, 5, 4, 4, 6, 6}
+)
+
+func TestPearson(t *testing.T) {
+	got, err := Pearson(corrX, corrY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.5711816; math.Abs(got.Coefficient-want) > 1e-7 {
+		t.Errorf("r: got %g; want %g", got.Coefficient, want)
+	}
+	// R gives t = 1.8411, df = 7, p-value = 0.1082.
+	if want := 0.1082; math.Abs(got.P-want) > 1e-4 {
+		t.Errorf("p: got %g; want %g", got.P, want)
+	}
+
+	perfect, err := Pearson([]float64{1, 2, 3, 4}, []float64{8, 6, 4, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perfect.Coefficient != -1 || perfect.P != 0 {
+		t.Errorf("perfectly correlated: got %+v; want r = -1, p = 0", perfect)
+	}
+
+	if _, err := Pearson([]float64{1, 2}, []float64{1, 2}); err != ErrSampleSize {
+		t.Errorf("Pearson of 2 pairs: got err %v; want ErrSampleSize", err)
+	}
+	if _, err := Pearson([]float64{1, 2, 3}, []float64{4, 4, 4}); err != ErrConstant {
+		t.Errorf("Pearson of constant y: got err %v; want ErrConstant", err)
+	}
+}
+
+func TestSpearman(t *testing.T) {
+	for _, tt := range []struct {
+		xs, ys []float64
+		want   float64
+	}{
+		{corrX, corrY, 0.6},
+		{tiedX, tiedY, 0.8616352201257862},
+		// Monotonic but not linear.
+		{[]float64{1, 2, 3, 4, 5}, []float64{1, 8, 27, 64, 125}, 1},
+	} {
+		got, err := Spearman(tt.xs, tt.ys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got.Coefficient-tt.want) > 1e-12 {
+			t.Errorf("Spearman(%v, %v): got %g; want %g", tt.xs, tt.ys, got.Coefficient, tt.want)
+		}
+	}
+}
+
+func TestKendall(t *testing.T) {
+	// Reference values computed by counting the concordant and discordant
+	// pairs directly.
+	for _, tt := range []struct {
+		xs, ys []float64
+		tau, p float64
+	}{
+		{corrX, corrY, 0.4444444444444444, 0.09529283802345662},
+		{tiedX, tiedY, 0.75, 0.0050012287331968825},
+	} {
+		got, err := Kendall(tt.xs, tt.ys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got.Coefficient-tt.tau) > 1e-12 {
+			t.Errorf("Kendall(%v, %v): got τ = %g; want %g", tt.xs, tt.ys, got.Coefficient, tt.tau)
+		}
+		if math.Abs(got.P-tt.p) > 1e-9 {
+			t.Errorf("Kendall(%v, %v): got p = %g; want %g", tt.xs, tt.ys, got.P, tt.p)
+		}
+	}
+	if _, err := Kendall([]float64{1, 1, 1}, []float64{1, 2, 3}); err != ErrConstant {
+		t.Errorf("Kendall of constant x: got err %v; want ErrConstant", err)
+	}
+}
+
+func TestKendallBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		n := 3 + r.Intn(50)
+		xs := make([]float64, n)
+		ys := make([]float64, n)
+		for j := range xs {
+			// Draw from a few values so that there are many ties.
+			xs[j] = float64(r.Intn(8))
+			ys[j] = float64(r.Intn(8))
+		}
+		got, err := Kendall(xs, ys)
+		if err == ErrConstant {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := kendallBruteForce(xs, ys); math.Abs(got.Coefficient-want) > 1e-12 {
+			t.Fatalf("Kendall(%v, %v): got %g; want %g", xs, ys, got.Coefficient, want)
+		}
+	}
+}
+
+func kendallBruteForce(xs, ys []float64) float64 {
+	var concordant, discordant, xTied, yTied float64
+	for i := range xs {
+		for j := i + 1; j < len(xs); j++ {
+			dx, dy := xs[i]-xs[j], ys[i]-ys[j]
+			switch {
+			case dx == 0 && dy == 0:
+			case dx == 0:
+				xTied++
+			case dy == 0:
+				yTied++
+			case dx*dy > 0:
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+	return (concordant - discordant) /
+		math.Sqrt((concordant+discordant+xTied)*(concordant+discordant+yTied))
+}
+
+func TestFitLine(t *testing.T) {
+	got, err := FitLine(corrX, corrY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"slope", got.Slope, 0.09718095394872218},
+		{"intercept", got.Intercept, -1.024107164903906},
+		{"R²", got.R2, 0.32624837170104126},
+		{"slope std. err.", got.SlopeErr, 0.05278467785031282},
+		{"intercept std. err.", got.InterceptErr, 2.540199819951979},
+		{"residual std. err.", got.ResidualErr, 0.8673229157764325},
+	} {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %g; want %g", tt.name, tt.got, tt.want)
+		}
+	}
+	// The t-test of the slope is equivalent to the test of Pearson's r.
+	if math.Abs(got.P-0.1082) > 1e-4 {
+		t.Errorf("p: got %g; want 0.1082", got.P)
+	}
+	if got.N != len(corrX) {
+		t.Errorf("N: got %d; want %d", got.N, len(corrX))
+	}
+
+	exact, err := FitLine([]float64{0, 1, 2, 3}, []float64{1, 3, 5, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exact.Slope != 2 || exact.Intercept != 1 || exact.R2 != 1 || exact.P != 0 {
+		t.Errorf("exact fit: got %+v", exact)
+	}
+	if got := exact.Predict(10); got != 21 {
+		t.Errorf("Predict(10): got %g; want 21", got)
+	}
+
+	if _, err := FitLine([]float64{2, 2, 2}, []float64{1, 2, 3}); err != ErrConstant {
+		t.Errorf("FitLine of constant x: got err %v; want ErrConstant", err)
+	}
+}
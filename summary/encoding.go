@@ -0,0 +1,285 @@
+package summary
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The binary encoding of a Summarizer is
+//
+//	magic      [4]byte "stsm"
+//	version    byte    (1)
+//	kind       byte    (0 for exact, 1 for approximate)
+//	count      uvarint
+//	min, max   float64
+//
+// followed, for an exact Summarizer, by
+//
+//	n          uvarint
+//	n times:
+//	  value    float64
+//	  weight   float64
+//
+// with values in increasing order or, for an approximate Summarizer, by
+//
+//	compression     float64
+//	mean            float64
+//	m2, m3, m4      float64 (sums of powers of deviations from the mean)
+//	n               uvarint
+//	n times:
+//	  mean          float64
+//	  weight        float64
+//
+// All float64s are IEEE 754 bit patterns in little-endian order.
+
+const (
+	encodingMagic   = "stsm"
+	encodingVersion = 1
+
+	kindExact  = 0
+	kindApprox = 1
+
+	maxDecodedCompression = 1e6
+)
+
+// MarshalBinary encodes the state of s so that it may be restored later by
+// UnmarshalBinary, possibly on another machine, and combined with other
+// Summarizers using Merge.
+func (s *Summarizer) MarshalBinary() ([]byte, error) {
+	b := []byte(encodingMagic)
+	b = append(b, encodingVersion)
+	if s.digest != nil {
+		b = append(b, kindApprox)
+	} else {
+		b = append(b, kindExact)
+	}
+	b = appendUvarint(b, uint64(s.count))
+	b = appendFloat(b, s.min)
+	b = appendFloat(b, s.max)
+
+	if s.digest != nil {
+		s.digest.compress()
+		b = appendFloat(b, s.digest.compression)
+		b = appendFloat(b, s.m.trueMean())
+		b = appendFloat(b, s.m.m2)
+		b = appendFloat(b, s.m.m3)
+		b = appendFloat(b, s.m.m4)
+		b = appendUvarint(b, uint64(len(s.digest.centroids)))
+		for _, c := range s.digest.centroids {
+			b = appendFloat(b, c.mean)
+			b = appendFloat(b, c.weight)
+		}
+		return b, nil
+	}
+
+	b = appendUvarint(b, uint64(s.tree.Len()))
+	s.walk(func(v, w float64) bool {
+		b = appendFloat(b, v)
+		b = appendFloat(b, w)
+		return true
+	})
+	return b, nil
+}
+
+// UnmarshalBinary replaces the state of s with the state encoded in data by
+// MarshalBinary. Whether s is exact or approximate is determined by data;
+// the QuantileMethod of s is unchanged.
+func (s *Summarizer) UnmarshalBinary(data []byte) error {
+	d := decoder{b: data}
+	if magic := d.bytes(len(encodingMagic)); string(magic) != encodingMagic {
+		return errors.New("summary: data is not an encoded Summarizer")
+	}
+	version := d.byte()
+	if version != encodingVersion {
+		return fmt.Errorf("summary: unsupported encoding version %d", version)
+	}
+	kind := d.byte()
+	count := int64(d.uvarint())
+	min := d.float()
+	max := d.float()
+
+	var t Summarizer
+	switch kind {
+	case kindExact:
+		t = *New()
+		n := d.uvarint()
+		var prev float64
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			v := d.float()
+			w := d.float()
+			if d.err != nil {
+				break
+			}
+			// The values are distinct and in increasing order.
+			if math.IsNaN(v) || i > 0 && v <= prev {
+				d.err = errors.New("summary: corrupt encoding (bad value)")
+				break
+			}
+			if !(w > 0 && !math.IsInf(w, 1)) {
+				d.err = errors.New("summary: corrupt encoding (bad weight)")
+				break
+			}
+			prev = v
+			t.tree.Set(v, w)
+			t.weight += w
+			if w < 1 {
+				t.light = true
+			}
+		}
+		t.stale = true
+	case kindApprox:
+		compression := d.float()
+		if d.err != nil {
+			break
+		}
+		// Far larger compressions than anyone uses would make NewApprox
+		// allocate an enormous buffer.
+		if !(compression >= 20 && compression <= maxDecodedCompression) {
+			d.err = errors.New("summary: corrupt encoding (bad compression)")
+			break
+		}
+		t = *NewApprox(compression)
+		t.m.shift = d.float()
+		t.m.m2 = d.float()
+		t.m.m3 = d.float()
+		t.m.m4 = d.float()
+		n := d.uvarint()
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			c := centroid{mean: d.float(), weight: d.float()}
+			if d.err == nil && !(c.weight > 0 && !math.IsInf(c.weight, 1)) {
+				d.err = errors.New("summary: corrupt encoding (bad weight)")
+			}
+			t.digest.centroids = append(t.digest.centroids, c)
+			t.digest.weight += c.weight
+		}
+		t.weight = t.digest.weight
+		t.m.n = t.weight
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("summary: corrupt encoding (unknown kind %d)", kind)
+		}
+	}
+	if d.err == nil && len(d.b) > 0 {
+		d.err = errors.New("summary: corrupt encoding (trailing data)")
+	}
+	if d.err != nil {
+		return d.err
+	}
+	t.count = count
+	t.min = min
+	t.max = max
+	t.method = s.method
+	*s = t
+	return nil
+}
+
+// Merge adds all the values recorded by other to s. An exact Summarizer
+// can be merged into an approximate one, but not the other way around.
+//
+// Merging exact Summarizers gives the same results as adding the values to
+// a single Summarizer. Merging approximate Summarizers gives the same count,
+// min, and max, but the estimates of the other statistics may differ
+// slightly.
+func (s *Summarizer) Merge(other *Summarizer) error {
+	if s.digest == nil && other.digest != nil {
+		return errors.New("summary: cannot merge an approximate Summarizer into an exact one")
+	}
+	if other.count == 0 {
+		return nil
+	}
+	s.count += other.count
+	if s.digest == nil {
+		other.walk(func(v, w float64) bool {
+			s.add(v, w)
+			return true
+		})
+		return nil
+	}
+
+	if s.weight == 0 || other.min < s.min {
+		s.min = other.min
+	}
+	if s.weight == 0 || other.max > s.max {
+		s.max = other.max
+	}
+	s.weight += other.weight
+	other.computeMoments()
+	s.m.merge(other.m)
+	if other.digest != nil {
+		other.digest.each(func(mean, weight float64) bool {
+			s.digest.add(mean, weight)
+			return true
+		})
+		return nil
+	}
+	other.walk(func(v, w float64) bool {
+		s.digest.add(v, w)
+		return true
+	})
+	return nil
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(b, buf[:n]...)
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+	return append(b, buf[:]...)
+}
+
+// A decoder reads values from an encoded Summarizer. After the first
+// error, it returns zero values.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+var errShort = errors.New("summary: corrupt encoding (too short)")
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = errShort
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	b := d.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) float() float64 {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errShort
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
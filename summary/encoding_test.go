@@ -0,0 +1,186 @@
+package summary
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range []*Summarizer{New(), NewApprox(100)} {
+		for i := 0; i < 10000; i++ {
+			s.Add(float64(rng.Intn(500)) / 10)
+		}
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s1 Summarizer
+		if err := s1.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if s1.Approx() != s.Approx() {
+			t.Fatalf("Approx: got %t; want %t", s1.Approx(), s.Approx())
+		}
+		checkSameStats(t, &s1, s)
+	}
+}
+
+func TestMergeExact(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	all := New()
+	var parts []*Summarizer
+	for i := 0; i < 5; i++ {
+		s := New()
+		for j := 0; j < 1000; j++ {
+			v := rng.NormFloat64() * 1e3
+			s.Add(v)
+			all.Add(v)
+		}
+		parts = append(parts, s)
+	}
+	merged := New()
+	for _, s := range parts {
+		if err := merged.Merge(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkSameStats(t, merged, all)
+	if got, want := merged.Histogram(10), all.Histogram(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("merged histogram: got %+v; want %+v", got, want)
+	}
+}
+
+func TestMergeApprox(t *testing.T) {
+	a := NewApprox(100)
+	b := NewApprox(100)
+	for i := 0; i < 10000; i++ {
+		a.Add(float64(i))
+		b.Add(float64(i + 10000))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Count(), int64(20000); got != want {
+		t.Errorf("Count: got %d; want %d", got, want)
+	}
+	if got, want := a.Max(), 19999.0; got != want {
+		t.Errorf("Max: got %g; want %g", got, want)
+	}
+	if got := a.Quantile(0.5); got < 9800 || got > 10200 {
+		t.Errorf("Quantile(0.5): got %g; want about 10000", got)
+	}
+	if err := New().Merge(a); err == nil {
+		t.Error("merging approximate into exact Summarizer succeeded")
+	}
+}
+
+func TestUnmarshalCorrupt(t *testing.T) {
+	s := New()
+	s.Add(1)
+	s.Add(2)
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{
+		nil,
+		[]byte("hello, world"),
+		b[:len(b)-1],
+		append(b[:len(b):len(b)], 0),
+	} {
+		var s1 Summarizer
+		if err := s1.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%q) succeeded", data)
+		}
+	}
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	for _, s := range []*Summarizer{New(), NewApprox(100)} {
+		for i := 0; i < 100; i++ {
+			s.Add(float64(i))
+		}
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n < len(b); n++ {
+			var s1 Summarizer
+			if err := s1.UnmarshalBinary(b[:n]); err == nil {
+				t.Errorf("UnmarshalBinary of the first %d of %d bytes (approx: %t) succeeded", n, len(b), s.Approx())
+			}
+		}
+	}
+}
+
+func TestUnmarshalBadCentroidWeight(t *testing.T) {
+	s := NewApprox(100)
+	s.Add(1)
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The encoding ends with the weight of the only centroid.
+	for _, w := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		data := appendFloat(b[:len(b)-8:len(b)-8], w)
+		var s1 Summarizer
+		if err := s1.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary with centroid weight %g succeeded", w)
+		}
+	}
+}
+
+func TestUnmarshalBadExactValues(t *testing.T) {
+	s := New()
+	s.Add(1)
+	s.Add(2)
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The encoding ends with the two values and their weights.
+	prefix := b[: len(b)-32 : len(b)-32]
+	nan := math.NaN()
+	for _, tt := range [][4]float64{
+		{1, 1, 1, 1},   // duplicate value
+		{2, 1, 1, 1},   // decreasing values
+		{nan, 1, 2, 1}, // NaN value
+		{1, 1, nan, 1},
+		{1, 1, 2, 0}, // bad weights
+		{1, 1, 2, -1},
+		{1, 1, 2, math.Inf(1)},
+		{1, nan, 2, 1},
+	} {
+		data := prefix
+		for _, f := range tt {
+			data = appendFloat(data, f)
+		}
+		var s1 Summarizer
+		if err := s1.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary with (value, weight) pairs %v succeeded", tt)
+		}
+	}
+}
+
+func checkSameStats(t *testing.T, got, want *Summarizer) {
+	t.Helper()
+	for _, check := range []struct {
+		name string
+		f    func(*Summarizer) float64
+	}{
+		{"Count", func(s *Summarizer) float64 { return float64(s.Count()) }},
+		{"Min", (*Summarizer).Min},
+		{"Max", (*Summarizer).Max},
+		{"Mean", (*Summarizer).Mean},
+		{"StdDev", (*Summarizer).StdDev},
+		{"Quantile(0.5)", func(s *Summarizer) float64 { return s.Quantile(0.5) }},
+		{"Quantile(0.99)", func(s *Summarizer) float64 { return s.Quantile(0.99) }},
+	} {
+		if g, w := check.f(got), check.f(want); g != w {
+			t.Errorf("%s: got %g; want %g", check.name, g, w)
+		}
+	}
+}
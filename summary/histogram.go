@@ -0,0 +1,179 @@
+package summary
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// A Histogram describes how values are distributed among a sequence of
+// adjacent buckets.
+type Histogram struct {
+	Buckets []Bucket
+
+	// Underflow and Overflow count the values that were less than the
+	// Start of the first bucket or greater than the End of the last
+	// bucket, respectively.
+	Underflow float64
+	Overflow  float64
+}
+
+// A Bucket is a single histogram bucket. It counts the values in the range
+// [Start, End), except for the last bucket in a Histogram, which includes
+// its End as well. Counts are the total weight of the values, so they are
+// whole numbers unless values were added with non-integer weights.
+type Bucket struct {
+	Start float64
+	End   float64
+	Count float64
+}
+
+// Histogram returns a histogram of the values with n equal-width buckets
+// spanning the range from the smallest to the largest value. It panics if n
+// is less than 1. For an approximate Summarizer, each t-digest centroid is
+// counted in the bucket containing its mean.
+func (s *Summarizer) Histogram(n int) *Histogram {
+	if n < 1 {
+		panic("summary: histogram must have at least one bucket")
+	}
+	return s.HistogramBounds(LinearBounds(s.min, s.max, n))
+}
+
+// HistogramBounds returns a histogram of the values where the buckets lie
+// between consecutive bounds. The bounds must be in increasing order and
+// there must be at least two of them. Values outside the bounds are counted
+// in the Histogram's Underflow and Overflow.
+func (s *Summarizer) HistogramBounds(bounds []float64) *Histogram {
+	if len(bounds) < 2 {
+		panic("summary: histogram must have at least two bounds")
+	}
+	n := len(bounds) - 1
+	h := &Histogram{Buckets: make([]Bucket, n)}
+	for i := range h.Buckets {
+		if bounds[i+1] < bounds[i] {
+			panic("summary: histogram bounds are not in increasing order")
+		}
+		h.Buckets[i].Start = bounds[i]
+		h.Buckets[i].End = bounds[i+1]
+	}
+	lo, hi := bounds[0], bounds[n]
+	bi := 0
+	s.walk(func(v, c float64) bool {
+		switch {
+		case v < lo:
+			h.Underflow += c
+			return true
+		case v > hi:
+			h.Overflow += c
+			return true
+		}
+		for bi < n-1 && v >= h.Buckets[bi].End {
+			bi++
+		}
+		h.Buckets[bi].Count += c
+		return true
+	})
+	return h
+}
+
+// LinearBounds returns the bounds of n equal-width buckets spanning [min,
+// max].
+func LinearBounds(min, max float64, n int) []float64 {
+	size := (max - min) / float64(n)
+	bounds := make([]float64, n+1)
+	for i := range bounds {
+		bounds[i] = min + float64(i)*size
+	}
+	bounds[n] = max
+	return bounds
+}
+
+// LogBounds returns the bounds of n buckets spanning [min, max] whose widths
+// increase exponentially, so that each bucket spans the same ratio. This is
+// useful for long-tailed data such as latencies. Both min and max must be
+// positive.
+func LogBounds(min, max float64, n int) ([]float64, error) {
+	if min <= 0 || max <= 0 {
+		return nil, errors.New("summary: logarithmic bounds must be positive")
+	}
+	lmin, lmax := math.Log(min), math.Log(max)
+	bounds := LinearBounds(lmin, lmax, n)
+	for i, b := range bounds {
+		bounds[i] = math.Exp(b)
+	}
+	bounds[0], bounds[n] = min, max
+	return bounds, nil
+}
+
+// NiceBounds returns the bounds of about n equal-width buckets spanning [min,
+// max] where the bucket width is 1, 2, or 5 times a power of 10 and each
+// bound is a multiple of the width. The first and last bounds may lie outside
+// [min, max].
+func NiceBounds(min, max float64, n int) []float64 {
+	if max <= min {
+		return []float64{min, max}
+	}
+	width := niceNumber((max - min) / float64(n))
+	lo := math.Floor(min / width)
+	hi := math.Ceil(max / width)
+	var bounds []float64
+	for i := lo; i <= hi; i++ {
+		bounds = append(bounds, clean(i*width))
+	}
+	return bounds
+}
+
+// NiceLogBounds returns bounds spanning [min, max] that follow the sequence
+// 1, 2, 5, 10, 20, 50, and so on, which makes for readable logarithmic
+// buckets. The first and last bounds may lie outside [min, max]. Both min and
+// max must be positive.
+func NiceLogBounds(min, max float64) ([]float64, error) {
+	if min <= 0 || max <= 0 {
+		return nil, errors.New("summary: logarithmic bounds must be positive")
+	}
+	mults := [...]float64{1, 2, 5}
+	// Start at the largest number in the sequence that is ≤ min.
+	e := int(math.Floor(math.Log10(min)))
+	if math.Pow10(e) > min {
+		e--
+	} else if math.Pow10(e+1) <= min {
+		e++
+	}
+	mi := 0
+	for mi+1 < len(mults) && mults[mi+1]*math.Pow10(e) <= min {
+		mi++
+	}
+	var bounds []float64
+	for {
+		b := clean(mults[mi] * math.Pow10(e))
+		bounds = append(bounds, b)
+		if b >= max && len(bounds) >= 2 {
+			return bounds, nil
+		}
+		if mi++; mi == len(mults) {
+			mi = 0
+			e++
+		}
+	}
+}
+
+// niceNumber rounds x up to 1, 2, or 5 times a power of 10.
+func niceNumber(x float64) float64 {
+	p := math.Pow10(int(math.Floor(math.Log10(x))))
+	for _, m := range []float64{1, 2, 5} {
+		if m*p >= x {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+// clean removes the floating-point noise that results from multiplying by
+// a fraction (as in 3*0.1 = 0.30000000000000004).
+func clean(x float64) float64 {
+	v, err := strconv.ParseFloat(strconv.FormatFloat(x, 'g', 12, 64), 64)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package summary
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	s := newTestSummarizer(0, 1, 1, 2, 5, 9, 10, 10)
+	got := s.Histogram(5)
+	want := &Histogram{
+		Buckets: []Bucket{
+			{Start: 0, End: 2, Count: 3},
+			{Start: 2, End: 4, Count: 1},
+			{Start: 4, End: 6, Count: 1},
+			{Start: 6, End: 8, Count: 0},
+			{Start: 8, End: 10, Count: 3},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Histogram(5): got %+v; want %+v", got, want)
+	}
+}
+
+func TestHistogramSingleValue(t *testing.T) {
+	s := newTestSummarizer(3, 3, 3)
+	h := s.Histogram(4)
+	var total float64
+	for _, b := range h.Buckets {
+		total += b.Count
+	}
+	if total != 3 {
+		t.Errorf("histogram of a single value has %g total count; want 3", total)
+	}
+}
+
+func TestHistogramBounds(t *testing.T) {
+	s := newTestSummarizer(-5, 0.5, 1, 3, 7, 10, 10, 11, 1e308)
+	got := s.HistogramBounds([]float64{1, 5, 10})
+	want := &Histogram{
+		Buckets: []Bucket{
+			{Start: 1, End: 5, Count: 2},
+			{Start: 5, End: 10, Count: 3},
+		},
+		Underflow: 2,
+		Overflow:  2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HistogramBounds: got %+v; want %+v", got, want)
+	}
+}
+
+func TestLogBounds(t *testing.T) {
+	got, err := LogBounds(1, 1000, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1, 10, 100, 1000}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9*want[i] {
+			t.Fatalf("LogBounds(1, 1000, 3): got %v; want %v", got, want)
+		}
+	}
+	if _, err := LogBounds(0, 10, 3); err == nil {
+		t.Error("LogBounds with min = 0 succeeded")
+	}
+}
+
+func TestNiceBounds(t *testing.T) {
+	for _, tt := range []struct {
+		min, max float64
+		n        int
+		want     []float64
+	}{
+		{0, 10, 5, []float64{0, 2, 4, 6, 8, 10}},
+		{93, 111, 10, []float64{92, 94, 96, 98, 100, 102, 104, 106, 108, 110, 112}},
+		{0.13, 0.61, 5, []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7}},
+		{-7, 3, 4, []float64{-10, -5, 0, 5}},
+	} {
+		got := NiceBounds(tt.min, tt.max, tt.n)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NiceBounds(%g, %g, %d): got %v; want %v", tt.min, tt.max, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNiceLogBounds(t *testing.T) {
+	for _, tt := range []struct {
+		min, max float64
+		want     []float64
+	}{
+		{1, 100, []float64{1, 2, 5, 10, 20, 50, 100}},
+		{3, 40, []float64{2, 5, 10, 20, 50}},
+		{0.03, 0.2, []float64{0.02, 0.05, 0.1, 0.2}},
+		{1000, 1000, []float64{1000, 2000}},
+	} {
+		got, err := NiceLogBounds(tt.min, tt.max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NiceLogBounds(%g, %g): got %v; want %v", tt.min, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package summary
+
+import (
+	"errors"
+	"math"
+
+	"github.com/cespare/stats/internal/dist"
+)
+
+// A TestResult is the outcome of a two-sample hypothesis test.
+type TestResult struct {
+	Statistic float64 // t for Welch's t-test; U for the Mann-Whitney U test
+	DF        float64 // degrees of freedom (Welch's t-test only)
+	P         float64 // two-sided p-value
+}
+
+// ErrSampleSize is returned by the hypothesis tests when a sample is too
+// small for the test to be meaningful.
+var ErrSampleSize = errors.New("summary: sample too small for test")
+
+// WelchTTest performs Welch's t-test of the null hypothesis that the values
+// in a and b come from distributions with equal means. Unlike Student's
+// t-test, it does not assume the two distributions have equal variances.
+// Each sample must have a total weight of at least two.
+//
+// In both tests, weights are treated as frequencies: a value added with
+// weight 3 counts as three observations.
+func WelchTTest(a, b *Summarizer) (TestResult, error) {
+	if a.weight < 2 || b.weight < 2 {
+		return TestResult{}, ErrSampleSize
+	}
+	na, nb := a.weight, b.weight
+	va := a.SampleVariance() / na
+	vb := b.SampleVariance() / nb
+	diff := a.Mean() - b.Mean()
+	se := math.Sqrt(va + vb)
+	if se == 0 {
+		// Both samples are constant.
+		if diff == 0 {
+			return TestResult{Statistic: 0, DF: na + nb - 2, P: 1}, nil
+		}
+		return TestResult{Statistic: math.Copysign(math.Inf(1), diff), DF: na + nb - 2, P: 0}, nil
+	}
+	t := diff / se
+	// Welch–Satterthwaite approximation of the degrees of freedom.
+	df := (va + vb) * (va + vb) / (va*va/(na-1) + vb*vb/(nb-1))
+	p := 2 * dist.StudentTCDF(-math.Abs(t), df)
+	return TestResult{Statistic: t, DF: df, P: p}, nil
+}
+
+// MannWhitneyU performs the Mann-Whitney U test (also known as the Wilcoxon
+// rank-sum test) of the null hypothesis that a value drawn from a is equally
+// likely to be greater or less than a value drawn from b. The test makes no
+// assumptions about the shape of the distributions.
+//
+// The reported statistic is U for a. The p-value uses the normal
+// approximation with corrections for ties and continuity, which is accurate
+// unless both samples are very small. If either Summarizer is approximate,
+// its t-digest centroids stand in for its values and the result is only an
+// estimate.
+func MannWhitneyU(a, b *Summarizer) (TestResult, error) {
+	if a.weight < 1 || b.weight < 1 {
+		return TestResult{}, ErrSampleSize
+	}
+	av, ac := a.values()
+	bv, bc := b.values()
+	var (
+		rankA float64 // sum of the ranks of the values in a
+		ties  float64 // sum of t³-t over each group of t tied values
+		below float64 // number of values less than the current one
+		i, j  int
+	)
+	for i < len(av) || j < len(bv) {
+		var v float64
+		switch {
+		case j == len(bv) || (i < len(av) && av[i] <= bv[j]):
+			v = av[i]
+		default:
+			v = bv[j]
+		}
+		var ca, cb float64
+		if i < len(av) && av[i] == v {
+			ca = ac[i]
+			i++
+		}
+		if j < len(bv) && bv[j] == v {
+			cb = bc[j]
+			j++
+		}
+		t := ca + cb
+		rankA += ca * (below + (t+1)/2) // the tied values share the mean rank
+		ties += t*t*t - t
+		below += t
+	}
+
+	na, nb := a.weight, b.weight
+	n := na + nb
+	u := rankA - na*(na+1)/2
+	mean := na * nb / 2
+	sd := math.Sqrt(na * nb / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sd == 0 {
+		return TestResult{Statistic: u, P: 1}, nil
+	}
+	z := math.Abs(u-mean) - 0.5
+	if z < 0 {
+		z = 0
+	}
+	p := 2 * dist.NormalCDF(-z/sd)
+	return TestResult{Statistic: u, P: math.Min(p, 1)}, nil
+}
+
+// values returns the distinct values of s in increasing order along with
+// their weights.
+func (s *Summarizer) values() (vs, weights []float64) {
+	s.walk(func(v, w float64) bool {
+		vs = append(vs, v)
+		weights = append(weights, w)
+		return true
+	})
+	return vs, weights
+}
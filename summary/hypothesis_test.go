@@ -0,0 +1,73 @@
+package summary
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	a := newTestSummarizer(19.8, 20.4, 19.6, 17.8, 18.5, 18.9, 18.3, 18.9, 19.5, 22.0)
+	b := newTestSummarizer(28.2, 26.6, 20.1, 23.3, 25.2, 22.1, 17.7, 27.6, 20.6, 13.7, 23.2, 17.5, 20.6, 18.0, 23.9, 21.6, 24.3, 20.4, 23.9, 13.3)
+	got, err := WelchTTest(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reference values computed from the sample means and variances.
+	if want := -2.225512; math.Abs(got.Statistic-want) > 1e-6 {
+		t.Errorf("t: got %g; want %g", got.Statistic, want)
+	}
+	if want := 24.5246; math.Abs(got.DF-want) > 1e-4 {
+		t.Errorf("df: got %g; want %g", got.DF, want)
+	}
+	if got.P < 0.01 || got.P > 0.05 {
+		t.Errorf("p: got %g; want between 0.01 and 0.05", got.P)
+	}
+
+	if _, err := WelchTTest(newTestSummarizer(1), b); err != ErrSampleSize {
+		t.Errorf("WelchTTest with 1 value: got err %v; want ErrSampleSize", err)
+	}
+
+	same, err := WelchTTest(newTestSummarizer(3, 3, 3), newTestSummarizer(3, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.P != 1 {
+		t.Errorf("identical constant samples: got p = %g; want 1", same.P)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	a := newTestSummarizer(1, 2, 3, 4, 5)
+	b := newTestSummarizer(6, 7, 8, 9, 10)
+	got, err := MannWhitneyU(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Statistic != 0 {
+		t.Errorf("U for fully separated samples: got %g; want 0", got.Statistic)
+	}
+	if got.P > 0.02 {
+		t.Errorf("p for fully separated samples: got %g; want < 0.02", got.P)
+	}
+
+	// With ties: a = {1, 2, 2, 3}, b = {2, 3, 3, 4}. The ranks are
+	// 1, 3, 3, 3, 3, 6, 6, 6, 8, so a's rank sum is 1+3+3+6 = 13 and
+	// U = 13 - 4*5/2 = 3.
+	a = newTestSummarizer(1, 2, 2, 3)
+	b = newTestSummarizer(2, 3, 3, 4)
+	got, err = MannWhitneyU(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Statistic != 3 {
+		t.Errorf("U with ties: got %g; want 3", got.Statistic)
+	}
+
+	got, err = MannWhitneyU(newTestSummarizer(5, 5), newTestSummarizer(5, 5, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.P != 1 {
+		t.Errorf("p for identical samples: got %g; want 1", got.P)
+	}
+}
@@ -0,0 +1,327 @@
+package summary
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cespare/stats/internal/dist"
+)
+
+// An Interval is a confidence interval for a statistic. A bound that cannot
+// be established from the sample is infinite.
+type Interval struct {
+	Lo, Hi float64
+}
+
+// ErrApprox is returned when a computation needs every value, which an
+// approximate Summarizer doesn't keep.
+var ErrApprox = errors.New("summary: not supported by an approximate Summarizer")
+
+func checkLevel(level float64) {
+	if !(level > 0 && level < 1) {
+		panic("summary: confidence level out of range (0, 1)")
+	}
+}
+
+// QuantileCI returns a distribution-free confidence interval for the
+// q-quantile at the given level (such as 0.95), formed by a pair of order
+// statistics. The number of values below the true q-quantile is binomially
+// distributed, so order statistics can be chosen such that each side of the
+// interval misses with probability at most (1-level)/2. If the sample is too
+// small for that to be possible on one side, that bound is infinite: for
+// example, the upper bound of a 95% interval for the 0.99 quantile needs at
+// least 368 values.
+//
+// QuantileCI returns ErrApprox for an approximate Summarizer and
+// ErrSampleSize if s is empty. It panics if q is outside [0, 1] or level is
+// outside (0, 1).
+func (s *Summarizer) QuantileCI(q, level float64) (Interval, error) {
+	if q < 0 || q > 1 {
+		panic("summary: quantile out of range [0, 1]")
+	}
+	checkLevel(level)
+	if s.digest != nil {
+		return Interval{}, ErrApprox
+	}
+	if s.count == 0 {
+		return Interval{}, ErrSampleSize
+	}
+	n, unit := s.sampleSize()
+	alpha := (1 - level) / 2
+	// The interval is [x(lo), x(hi)] where lo is the largest rank with
+	// P(B < lo) ≤ alpha and hi is the smallest rank with P(B < hi) ≥
+	// 1-alpha, for B ~ Binomial(n, q). Rank 0 and rank n+1 mean that there
+	// is no such order statistic.
+	cdf := func(k int) float64 { return dist.BinomialCDF(float64(k-1), n, q) }
+	m := int(n)
+	lo := sort.Search(m+1, func(k int) bool { return cdf(k) > alpha }) - 1
+	hi := sort.Search(m+1, func(k int) bool { return cdf(k) >= 1-alpha })
+	iv := Interval{Lo: math.Inf(-1), Hi: math.Inf(1)}
+	if lo >= 1 {
+		iv.Lo = s.orderStat(float64(lo), unit)
+	}
+	if hi <= m {
+		iv.Hi = s.orderStat(float64(hi), unit)
+	}
+	return iv, nil
+}
+
+// maxScaledSize is the largest sample size that sampleSize gives for
+// weights scaled by rankUnit, unless the count is larger.
+const maxScaledSize = 10000
+
+// sampleSize gives the size of the sample that the confidence intervals
+// treat an exact Summarizer as, and the weight of each of its values. This is
+// the number of ranks (see rankUnit), except that if the weights are scaled
+// up, the sample is no larger than the count or maxScaledSize, whichever is
+// larger; otherwise a tiny weight would make it arbitrarily large.
+func (s *Summarizer) sampleSize() (n, unit float64) {
+	unit = s.rankUnit()
+	n = math.Floor(s.weight/unit + 0.5)
+	if max := math.Max(float64(s.count), maxScaledSize); unit < 1 && n > max {
+		n = max
+		unit = s.weight / n
+	}
+	return n, unit
+}
+
+// A BootstrapInterval is a bootstrap confidence interval for a statistic,
+// computed in two ways from the same resamples.
+type BootstrapInterval struct {
+	// Percentile takes the bounds directly from the quantiles of the
+	// bootstrap distribution of the statistic.
+	Percentile Interval
+	// BCa (bias-corrected and accelerated) adjusts the quantiles for bias
+	// in the bootstrap distribution and for the rate at which the standard
+	// error of the statistic changes with its value. It is more accurate
+	// for skewed statistics such as the mean of long-tailed values or high
+	// quantiles.
+	BCa Interval
+}
+
+// A BootstrapResult holds bootstrap confidence intervals for the mean and
+// for quantiles.
+type BootstrapResult struct {
+	Mean      BootstrapInterval
+	Quantiles []BootstrapInterval // in the same order as the requested quantiles
+}
+
+// Bootstrap computes bootstrap confidence intervals at the given level
+// (such as 0.95) for the mean and for each quantile in qs. It draws iters
+// resamples, each the same size as the input, using rng; the quantiles of
+// the resamples are computed using the Summarizer's QuantileMethod. For
+// weighted values, weights are treated as frequencies (scaled as for
+// Quantile if any value has a weight less than 1, though then the resamples
+// are no larger than the count or 10000 values, whichever is larger).
+//
+// Each resample takes time proportional to n + k, where n is the resample
+// size and k the number of distinct values.
+//
+// Bootstrap returns ErrApprox for an approximate Summarizer and
+// ErrSampleSize if the total weight is less than 2. It panics if any q is
+// outside [0, 1], level is outside (0, 1), or iters is less than 1.
+func (s *Summarizer) Bootstrap(qs []float64, level float64, iters int, rng *rand.Rand) (BootstrapResult, error) {
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			panic("summary: quantile out of range [0, 1]")
+		}
+	}
+	checkLevel(level)
+	if iters < 1 {
+		panic("summary: bootstrap needs at least one iteration")
+	}
+	if s.digest != nil {
+		return BootstrapResult{}, ErrApprox
+	}
+	size, unit := s.sampleSize()
+	n := int(size)
+	if n < 2 {
+		return BootstrapResult{}, ErrSampleSize
+	}
+
+	vs, ws := s.values()
+	draw := newAliasTable(ws)
+	for i := range ws {
+		ws[i] /= unit // in ranks, for the jackknife
+	}
+	mean := s.Mean()
+
+	// Draw the resamples, keeping the statistics of each.
+	meanReps := make([]float64, iters)
+	quantReps := make([][]float64, len(qs))
+	for i := range quantReps {
+		quantReps[i] = make([]float64, iters)
+	}
+	counts := make([]int, len(vs)) // cumulative counts of each value, after the first loop
+	for it := 0; it < iters; it++ {
+		for i := range counts {
+			counts[i] = 0
+		}
+		for d := 0; d < n; d++ {
+			counts[draw(rng)]++
+		}
+		var sum float64 // of differences from the mean, for accuracy
+		c := 0
+		for i, k := range counts {
+			sum += float64(k) * (vs[i] - mean)
+			c += k
+			counts[i] = c
+		}
+		meanReps[it] = mean + sum/float64(n)
+		stat := func(k float64) float64 {
+			k = math.Max(1, math.Min(k, float64(n)))
+			i := sort.Search(len(counts), func(i int) bool { return float64(counts[i]) >= k })
+			return vs[i]
+		}
+		for i, q := range qs {
+			quantReps[i][it] = interpolateOrderStats(s.method, q, float64(n), stat)
+		}
+	}
+
+	// Compute the jackknife statistics, leaving out one rank's worth of
+	// each distinct value in turn, for the BCa acceleration.
+	jackMeans := make([]float64, len(vs))
+	for i, v := range vs {
+		jackMeans[i] = (s.weight*mean - unit*v) / (s.weight - unit)
+	}
+	res := BootstrapResult{
+		Mean:      bootstrapInterval(meanReps, mean, acceleration(jackMeans, ws), level),
+		Quantiles: make([]BootstrapInterval, len(qs)),
+	}
+	ests := s.Quantiles(qs)
+	jack := make([]float64, len(vs))
+	for i, q := range qs {
+		for j, v := range vs {
+			// Removing a rank of v shifts the ranks of v and the values
+			// above it down by one.
+			stat := func(k float64) float64 {
+				if x := s.orderStat(k, unit); x < v {
+					return x
+				}
+				return s.orderStat(k+1, unit)
+			}
+			jack[j] = interpolateOrderStats(s.method, q, s.weight/unit-1, stat)
+		}
+		res.Quantiles[i] = bootstrapInterval(quantReps[i], ests[i], acceleration(jack, ws), level)
+	}
+	return res, nil
+}
+
+// newAliasTable returns a function that draws an index of ws at random with
+// probability proportional to its weight, in constant time, using Vose's
+// alias method.
+func newAliasTable(ws []float64) func(*rand.Rand) int {
+	k := len(ws)
+	var total float64
+	for _, w := range ws {
+		total += w
+	}
+	// Each of the k slots holds index i with probability prob[i] and
+	// alias[i] otherwise.
+	prob := make([]float64, k)
+	alias := make([]int, k)
+	var small, large []int
+	for i, w := range ws {
+		prob[i] = w * float64(k) / total
+		if prob[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		alias[s] = l
+		prob[l] -= 1 - prob[s]
+		if prob[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Any remaining slots are full, up to rounding error.
+	for _, i := range append(small, large...) {
+		prob[i] = 1
+	}
+	return func(rng *rand.Rand) int {
+		x := rng.Float64() * float64(k)
+		i := int(x)
+		if x-float64(i) >= prob[i] {
+			return alias[i]
+		}
+		return i
+	}
+}
+
+// acceleration estimates the BCa acceleration from the jackknife values of
+// a statistic, each with the given weight.
+func acceleration(jack, weights []float64) float64 {
+	var mean, total float64
+	for i, v := range jack {
+		mean += weights[i] * v
+		total += weights[i]
+	}
+	mean /= total
+	var num, den float64
+	for i, v := range jack {
+		d := mean - v
+		num += weights[i] * d * d * d
+		den += weights[i] * d * d
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / (6 * math.Pow(den, 1.5))
+}
+
+// bootstrapInterval computes the confidence intervals from the bootstrap
+// replicates of a statistic (which it sorts), its estimate from the
+// original sample, and its acceleration.
+func bootstrapInterval(reps []float64, est, accel, level float64) BootstrapInterval {
+	sort.Float64s(reps)
+	alpha := (1 - level) / 2
+	iv := BootstrapInterval{
+		Percentile: Interval{
+			Lo: sortedQuantile(reps, alpha),
+			Hi: sortedQuantile(reps, 1-alpha),
+		},
+	}
+
+	// The bias correction is the normal quantile of the fraction of the
+	// replicates below the estimate, counting ties as half below. It is
+	// kept finite by treating the fraction as at least half a replicate
+	// from 0 or 1.
+	below := sort.SearchFloat64s(reps, est)
+	equal := sort.Search(len(reps), func(i int) bool { return reps[i] > est }) - below
+	b := float64(len(reps))
+	frac := (float64(below) + float64(equal)/2) / b
+	frac = math.Max(0.5/b, math.Min(frac, 1-0.5/b))
+	z0 := dist.NormalQuantile(frac)
+	adjust := func(p float64) float64 {
+		z := z0 + dist.NormalQuantile(p)
+		return dist.NormalCDF(z0 + z/(1-accel*z))
+	}
+	iv.BCa = Interval{
+		Lo: sortedQuantile(reps, adjust(alpha)),
+		Hi: sortedQuantile(reps, adjust(1-alpha)),
+	}
+	return iv
+}
+
+// sortedQuantile returns the p-quantile of the sorted values vs,
+// interpolating linearly between them.
+func sortedQuantile(vs []float64, p float64) float64 {
+	if math.IsNaN(p) {
+		return math.NaN()
+	}
+	p = math.Max(0, math.Min(p, 1))
+	pos := p * float64(len(vs)-1)
+	i := int(pos)
+	if i >= len(vs)-1 {
+		return vs[len(vs)-1]
+	}
+	h := pos - float64(i)
+	return vs[i] + h*(vs[i+1]-vs[i])
+}
@@ -0,0 +1,177 @@
+package summary
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestQuantileCI(t *testing.T) {
+	s := New()
+	for i := 1; i <= 10; i++ {
+		s.Add(float64(i))
+	}
+	// For n = 10, the 95% interval for the median is [x(2), x(9)], with
+	// coverage 1 - 2*P(B ≤ 1) = 1 - 22/1024.
+	got, err := s.QuantileCI(0.5, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Interval{2, 9}); got != want {
+		t.Errorf("QuantileCI(0.5, 0.95): got %v; want %v", got, want)
+	}
+
+	// The upper bound for the 0.99 quantile needs at least 368 values.
+	for _, tt := range []struct {
+		n       int
+		bounded bool
+	}{
+		{200, false},
+		{367, false},
+		{368, true},
+	} {
+		s := New()
+		for i := 1; i <= tt.n; i++ {
+			s.Add(float64(i))
+		}
+		got, err := s.QuantileCI(0.99, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.IsInf(got.Lo, 0) || got.Lo > s.Quantile(0.99) {
+			t.Errorf("n = %d: got lower bound %g; want a finite value ≤ %g", tt.n, got.Lo, s.Quantile(0.99))
+		}
+		if bounded := !math.IsInf(got.Hi, 1); bounded != tt.bounded {
+			t.Errorf("n = %d: got upper bound %g; want bounded = %t", tt.n, got.Hi, tt.bounded)
+		}
+	}
+
+	if _, err := New().QuantileCI(0.5, 0.95); err != ErrSampleSize {
+		t.Errorf("QuantileCI of empty Summarizer: got err %v; want ErrSampleSize", err)
+	}
+	if _, err := NewApprox(100).QuantileCI(0.5, 0.95); err != ErrApprox {
+		t.Errorf("QuantileCI of approximate Summarizer: got err %v; want ErrApprox", err)
+	}
+}
+
+func TestBootstrapNormal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := New()
+	for i := 0; i < 2000; i++ {
+		s.Add(100 + 10*r.NormFloat64())
+	}
+	res, err := s.Bootstrap([]float64{0.5, 0.9}, 0.95, 1000, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// For normal values, the mean's interval should be close to the
+	// t-interval.
+	halfWidth := 1.96 * s.StdErr()
+	for _, iv := range []Interval{res.Mean.Percentile, res.Mean.BCa} {
+		if !intervalNear(iv, Interval{s.Mean() - halfWidth, s.Mean() + halfWidth}, 0.1*halfWidth) {
+			t.Errorf("mean: got %v; want about %g ± %g", iv, s.Mean(), halfWidth)
+		}
+	}
+	// The quantiles' intervals should be close to the order statistic
+	// intervals.
+	for i, q := range []float64{0.5, 0.9} {
+		want, err := s.QuantileCI(q, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tol := 0.25 * (want.Hi - want.Lo)
+		for _, iv := range []Interval{res.Quantiles[i].Percentile, res.Quantiles[i].BCa} {
+			if !intervalNear(iv, want, tol) {
+				t.Errorf("quantile %g: got %v; want about %v", q, iv, want)
+			}
+		}
+	}
+
+	// The same seed gives the same result.
+	again, err := s.Bootstrap([]float64{0.5, 0.9}, 0.95, 1000, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, again) {
+		t.Errorf("Bootstrap with the same seed: got %+v, then %+v", res, again)
+	}
+}
+
+func TestBootstrapSkewed(t *testing.T) {
+	// For right-skewed values, BCa moves the mean's interval to the right
+	// of the percentile interval.
+	r := rand.New(rand.NewSource(1))
+	s := New()
+	for i := 0; i < 100; i++ {
+		s.Add(math.Exp(2 * r.NormFloat64()))
+	}
+	res, err := s.Bootstrap(nil, 0.95, 1000, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, bca := res.Mean.Percentile, res.Mean.BCa
+	if !(bca.Lo > p.Lo && bca.Hi > p.Hi) {
+		t.Errorf("got percentile interval %v and BCa interval %v; want BCa to the right", p, bca)
+	}
+}
+
+func TestBootstrapErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if _, err := newTestSummarizer(1).Bootstrap(nil, 0.95, 100, rng); err != ErrSampleSize {
+		t.Errorf("Bootstrap of one value: got err %v; want ErrSampleSize", err)
+	}
+	a := NewApprox(100)
+	a.Add(1)
+	a.Add(2)
+	if _, err := a.Bootstrap(nil, 0.95, 100, rng); err != ErrApprox {
+		t.Errorf("Bootstrap of approximate Summarizer: got err %v; want ErrApprox", err)
+	}
+}
+
+func TestIntervalsTinyWeight(t *testing.T) {
+	// A tiny weight scales up the ranks, but not the size of the sample.
+	s := New()
+	for i := 1; i <= 10; i++ {
+		s.Add(float64(i))
+	}
+	s.AddWeighted(11, 1e-6)
+	if n, unit := s.sampleSize(); n != maxScaledSize || unit != s.Weight()/maxScaledSize {
+		t.Errorf("sampleSize: got %g, %g; want %d, %g", n, unit, maxScaledSize, s.Weight()/maxScaledSize)
+	}
+	got, err := s.QuantileCI(0.5, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(got.Lo >= 1 && got.Hi <= 10 && got.Lo < got.Hi) {
+		t.Errorf("QuantileCI(0.5, 0.95): got %v; want a finite interval within [1, 10]", got)
+	}
+	res, err := s.Bootstrap([]float64{0.5}, 0.95, 100, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iv := res.Mean.Percentile; !(iv.Lo > 4 && iv.Hi < 7) {
+		t.Errorf("Bootstrap mean interval: got %v; want an interval around 5.5", iv)
+	}
+}
+
+func TestAliasTable(t *testing.T) {
+	ws := []float64{1, 0, 5, 0.5, 3.5}
+	draw := newAliasTable(ws)
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]float64, len(ws))
+	const n = 100000
+	for i := 0; i < n; i++ {
+		counts[draw(rng)]++
+	}
+	for i, w := range ws {
+		want := w / 10 * n
+		if math.Abs(counts[i]-want) > 4*math.Sqrt(want)+0.5 {
+			t.Errorf("index %d (weight %g): drawn %g times; want about %g", i, w, counts[i], want)
+		}
+	}
+}
+
+func intervalNear(got, want Interval, tol float64) bool {
+	return math.Abs(got.Lo-want.Lo) <= tol && math.Abs(got.Hi-want.Hi) <= tol
+}
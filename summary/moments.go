@@ -0,0 +1,118 @@
+package summary
+
+import "math"
+
+// moments tracks the total weight, mean, and central moments of a sequence
+// of weighted values using the numerically stable pairwise update of Chan et
+// al., extended to the third and fourth moments by Pébay. Unlike the naive
+// approach of summing powers of the values, it doesn't lose precision when
+// the values are large relative to their spread.
+//
+// To preserve precision even when the mean can't be represented much more
+// accurately than the spread of the values, the mean is tracked relative to
+// a shift: the first value recorded. Subtracting the shift from nearby values
+// is exact. The reported mean comes from a plain sum of the shifted values,
+// which (unlike the running mean) is exact for typical inputs such as small
+// integers.
+type moments struct {
+	n     float64 // total weight
+	shift float64
+	sum   float64 // weighted sum of the values minus shift
+	mean  float64 // running mean, relative to shift
+	m2    float64 // sum of weighted squared deviations from the mean
+	m3    float64 // ... cubed deviations
+	m4    float64 // ... fourth powers of deviations
+}
+
+// add records the value v with weight w.
+func (m *moments) add(v, w float64) {
+	m.merge(moments{n: w, shift: v})
+}
+
+// trueMean returns the mean of the values.
+func (m *moments) trueMean() float64 {
+	return m.shift + m.sum/m.n
+}
+
+// exactMoments computes the moments of the values visited by walk using two
+// passes (one to find the mean and another to sum the powers of the
+// deviations from it), which is more accurate than updating the moments one
+// value at a time.
+func exactMoments(walk func(fn func(v, w float64) bool)) moments {
+	var m moments
+	walk(func(v, w float64) bool {
+		if m.n == 0 {
+			m.shift = v
+		}
+		m.n += w
+		m.sum += (v - m.shift) * w
+		return true
+	})
+	if m.n == 0 {
+		return m
+	}
+	m.mean = m.sum / m.n
+	var sumDev float64
+	walk(func(v, w float64) bool {
+		d := (v - m.shift) - m.mean
+		d2 := d * d
+		sumDev += d * w
+		m.m2 += d2 * w
+		m.m3 += d2 * d * w
+		m.m4 += d2 * d2 * w
+		return true
+	})
+	// Correct for rounding error in the mean.
+	m.m2 -= sumDev * sumDev / m.n
+	return m
+}
+
+// merge combines the moments of another sequence with m.
+func (m *moments) merge(o moments) {
+	if o.n == 0 {
+		return
+	}
+	if m.n == 0 {
+		*m = o
+		return
+	}
+	na, nb := m.n, o.n
+	n := na + nb
+	d := (o.shift - m.shift) + o.mean - m.mean
+	dn := d / n
+	m4 := m.m4 + o.m4 +
+		d*dn*dn*dn*na*nb*(na*na-na*nb+nb*nb) +
+		6*dn*dn*(na*na*o.m2+nb*nb*m.m2) +
+		4*dn*(na*o.m3-nb*m.m3)
+	m3 := m.m3 + o.m3 +
+		d*dn*dn*na*nb*(na-nb) +
+		3*dn*(na*o.m2-nb*m.m2)
+	m.m2 += o.m2 + d*dn*na*nb
+	m.m3 = m3
+	m.m4 = m4
+	m.sum += o.sum + (o.shift-m.shift)*nb
+	m.mean += dn * nb
+	m.n = n
+}
+
+func (m *moments) variance() float64 {
+	if m.n == 0 {
+		return math.NaN()
+	}
+	return m.m2 / m.n
+}
+
+func (m *moments) sampleVariance() float64 {
+	if m.n == 0 {
+		return math.NaN()
+	}
+	return m.m2 / (m.n - 1)
+}
+
+func (m *moments) skewness() float64 {
+	return math.Sqrt(m.n) * m.m3 / math.Pow(m.m2, 1.5)
+}
+
+func (m *moments) kurtosis() float64 {
+	return m.n*m.m4/(m.m2*m.m2) - 3
+}
@@ -0,0 +1,137 @@
+package summary
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoments(t *testing.T) {
+	// Reference values computed with exact rational arithmetic.
+	for _, tt := range []struct {
+		name     string
+		vs       []float64
+		mean     float64
+		variance float64
+		sampVar  float64
+		skewness float64
+		kurtosis float64
+		stdErr   float64
+	}{
+		{
+			name:     "small",
+			vs:       []float64{2, 8, 0, 4, 1, 9, 9, 0},
+			mean:     4.125,
+			variance: 13.859375,
+			sampVar:  15.839285714285714,
+			skewness: 0.26505541226985735,
+			kurtosis: -1.6660010752838508,
+			stdErr:   1.4070930012922793,
+		},
+		{
+			// The naive formula for the variance gives -128 here.
+			name:     "large offset",
+			vs:       []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16},
+			mean:     1e9 + 10,
+			variance: 22.5,
+			sampVar:  30,
+			skewness: 0,
+			kurtosis: -1.64,
+			stdErr:   2.7386127875258306,
+		},
+	} {
+		for _, s := range []*Summarizer{New(), NewApprox(100)} {
+			for _, v := range tt.vs {
+				s.Add(v)
+			}
+			for _, check := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"Mean", s.Mean(), tt.mean},
+				{"Variance", s.Variance(), tt.variance},
+				{"SampleVariance", s.SampleVariance(), tt.sampVar},
+				{"StdDev", s.StdDev(), math.Sqrt(tt.variance)},
+				{"Skewness", s.Skewness(), tt.skewness},
+				{"Kurtosis", s.Kurtosis(), tt.kurtosis},
+				{"StdErr", s.StdErr(), tt.stdErr},
+				{"CV", s.CV(), math.Sqrt(tt.variance) / tt.mean},
+			} {
+				if !closeTo(check.got, check.want) && math.Abs(check.got-check.want) > 1e-12 {
+					t.Errorf("%s (approx=%t): %s: got %g; want %g", tt.name, s.Approx(), check.name, check.got, check.want)
+				}
+			}
+		}
+	}
+}
+
+func TestMomentsTimestamps(t *testing.T) {
+	// Unix timestamps in nanoseconds: the spacing between adjacent
+	// float64s here is 256, so the mean can't be represented exactly.
+	const base = 1.7e18
+	s := New()
+	for _, k := range []float64{0, 1, 2, 3, 5, 8, 13} {
+		s.Add(base + k*1024)
+	}
+	// Reference value computed with exact rational arithmetic.
+	want := math.Sqrt(18831568.979591835)
+	if got := s.StdDev(); math.IsNaN(got) || math.Abs(got-want) > 1e-3*want {
+		t.Errorf("StdDev: got %g; want %g", got, want)
+	}
+}
+
+func TestMomentsEmpty(t *testing.T) {
+	s := New()
+	for _, check := range []struct {
+		name string
+		v    float64
+	}{
+		{"Variance", s.Variance()},
+		{"SampleVariance", s.SampleVariance()},
+		{"Skewness", s.Skewness()},
+		{"Kurtosis", s.Kurtosis()},
+		{"CV", s.CV()},
+		{"StdErr", s.StdErr()},
+	} {
+		if !math.IsNaN(check.v) {
+			t.Errorf("%s of empty Summarizer: got %g; want NaN", check.name, check.v)
+		}
+	}
+	s.Add(3)
+	if got := s.Variance(); got != 0 {
+		t.Errorf("Variance of a single value: got %g; want 0", got)
+	}
+	if got := s.SampleVariance(); !math.IsNaN(got) {
+		t.Errorf("SampleVariance of a single value: got %g; want NaN", got)
+	}
+}
+
+func TestMomentsMerge(t *testing.T) {
+	vs := []float64{2, 8, 0, 4, 1, 9, 9, 0, -3, 12.5}
+	all := NewApprox(100)
+	a := NewApprox(100)
+	b := NewApprox(100)
+	for i, v := range vs {
+		all.Add(v)
+		if i < 3 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range []struct {
+		name string
+		f    func(*Summarizer) float64
+	}{
+		{"Mean", (*Summarizer).Mean},
+		{"Variance", (*Summarizer).Variance},
+		{"Skewness", (*Summarizer).Skewness},
+		{"Kurtosis", (*Summarizer).Kurtosis},
+	} {
+		if got, want := check.f(a), check.f(all); !closeTo(got, want) {
+			t.Errorf("%s after merge: got %g; want %g", check.name, got, want)
+		}
+	}
+}
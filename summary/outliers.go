@@ -0,0 +1,140 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+)
+
+// An OutlierMethod is a rule for deciding which values are outliers.
+type OutlierMethod int
+
+const (
+	// IQR (Tukey's fences) flags values more than k interquartile ranges
+	// below the first quartile or above the third quartile.
+	IQR OutlierMethod = iota
+	// MAD flags values whose modified z-score, based on the median
+	// absolute deviation scaled to estimate the standard deviation, is
+	// more than k in magnitude. It is the most robust of the methods: the
+	// median absolute deviation is unaffected by up to half of the values
+	// being outliers.
+	MAD
+	// ZScore flags values more than k standard deviations from the mean.
+	// The outliers themselves inflate the standard deviation, so it can
+	// miss them in small samples.
+	ZScore
+)
+
+func (m OutlierMethod) String() string {
+	switch m {
+	case IQR:
+		return "iqr"
+	case MAD:
+		return "mad"
+	case ZScore:
+		return "zscore"
+	}
+	return fmt.Sprintf("OutlierMethod(%d)", int(m))
+}
+
+// DefaultThreshold returns the conventional k for the method: 1.5 for IQR,
+// 3.5 for MAD (as recommended by Iglewicz and Hoaglin), and 3 for ZScore.
+func (m OutlierMethod) DefaultThreshold() float64 {
+	switch m {
+	case IQR:
+		return 1.5
+	case MAD:
+		return 3.5
+	case ZScore:
+		return 3
+	}
+	panic("summary: invalid outlier method")
+}
+
+// OutlierFences returns the bounds outside of which method m with threshold
+// k considers values to be outliers: a value is an outlier if it is less
+// than lo or greater than hi. It returns NaNs if s is empty. For an
+// approximate Summarizer, the fences are estimates.
+func (s *Summarizer) OutlierFences(m OutlierMethod, k float64) (lo, hi float64) {
+	if s.count == 0 {
+		return math.NaN(), math.NaN()
+	}
+	switch m {
+	case IQR:
+		qs := s.Quantiles([]float64{0.25, 0.75})
+		iqr := qs[1] - qs[0]
+		return qs[0] - k*iqr, qs[1] + k*iqr
+	case MAD:
+		med := s.Quantile(0.5)
+		d := k * madScale * s.MAD()
+		return med - d, med + d
+	case ZScore:
+		mean, d := s.Mean(), k*s.StdDev()
+		return mean - d, mean + d
+	}
+	panic("summary: invalid outlier method")
+}
+
+// Trimmed returns a new Summarizer, of the same kind as s, holding the
+// values of s without the lowest and highest fraction p of them. The weight
+// removed from each end is ⌊pW⌋, where W is the total weight, so for
+// unweighted values, whole values are removed as for a trimmed mean. The
+// count is reduced in proportion to the weight removed. Trimmed panics if p
+// is outside [0, 0.5).
+func (s *Summarizer) Trimmed(p float64) *Summarizer {
+	return s.cut(p, false)
+}
+
+// Winsorized is like Trimmed, but instead of removing the lowest and
+// highest values, it replaces them with the lowest and highest of the
+// remaining values.
+func (s *Summarizer) Winsorized(p float64) *Summarizer {
+	return s.cut(p, true)
+}
+
+func (s *Summarizer) cut(p float64, winsorize bool) *Summarizer {
+	if !(p >= 0 && p < 0.5) {
+		panic("summary: trimmed fraction out of range [0, 0.5)")
+	}
+	out := New()
+	if s.digest != nil {
+		out = NewApprox(s.digest.compression)
+	}
+	out.method = s.method
+	if s.count == 0 {
+		return out
+	}
+
+	// The kept values are those whose ranks (in cumulative weight) overlap
+	// [g, W-g]. Find the lowest and highest of them.
+	g := math.Floor(p * s.weight)
+	lo, hi := math.NaN(), math.NaN()
+	var cum float64
+	s.walk(func(v, w float64) bool {
+		if cum+w > g && math.IsNaN(lo) {
+			lo = v
+		}
+		if cum < s.weight-g {
+			hi = v
+		}
+		cum += w
+		return true
+	})
+
+	cum = 0
+	s.walk(func(v, w float64) bool {
+		kept := math.Min(cum+w, s.weight-g) - math.Max(cum, g)
+		cum += w
+		if kept > 0 {
+			out.add(v, kept)
+		}
+		return true
+	})
+	if winsorize {
+		out.add(lo, g)
+		out.add(hi, g)
+		out.count = s.count
+	} else {
+		out.count = int64(math.Round(float64(s.count) * out.weight / s.weight))
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package summary
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOutlierFences(t *testing.T) {
+	var vs []float64
+	for i := 1; i <= 10; i++ {
+		vs = append(vs, float64(i))
+	}
+	s := newTestSummarizer(vs...)
+	// The quartiles are 3 and 8.
+	if lo, hi := s.OutlierFences(IQR, 1.5); lo != -4.5 || hi != 15.5 {
+		t.Errorf("IQR fences: got [%g, %g]; want [-4.5, 15.5]", lo, hi)
+	}
+
+	// Garbage values: the MAD fences are unaffected by them, while the
+	// sentinel value makes the standard deviation infinite.
+	s = newTestSummarizer(append(vs, -50, 1e308)...)
+	lo, hi := s.OutlierFences(MAD, 3.5)
+	if !(lo > -50 && lo < 1 && hi > 10 && hi < 1e308) {
+		t.Errorf("MAD fences: got [%g, %g]; want them to exclude -50 and 1e308 only", lo, hi)
+	}
+	if lo, hi := s.OutlierFences(ZScore, 3); !(lo <= -50 && hi >= 1e308) {
+		t.Errorf("z-score fences: got [%g, %g]; want them to miss the outliers", lo, hi)
+	}
+
+	for _, m := range []OutlierMethod{IQR, MAD, ZScore} {
+		if lo, hi := New().OutlierFences(m, m.DefaultThreshold()); !math.IsNaN(lo) || !math.IsNaN(hi) {
+			t.Errorf("%s fences of empty Summarizer: got [%g, %g]; want NaN", m, lo, hi)
+		}
+	}
+}
+
+func TestTrimmed(t *testing.T) {
+	s := newTestSummarizer(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	for _, p := range []float64{0.1, 0.15} {
+		got := s.Trimmed(p)
+		if got.Count() != 8 || got.Min() != 2 || got.Max() != 9 || got.Mean() != 5.5 {
+			t.Errorf("Trimmed(%g): got count %d, min %g, max %g, mean %g; want 8, 2, 9, 5.5",
+				p, got.Count(), got.Min(), got.Max(), got.Mean())
+		}
+	}
+	if got := s.Trimmed(0); got.Count() != 10 || got.Mean() != s.Mean() {
+		t.Errorf("Trimmed(0): got count %d, mean %g; want the original", got.Count(), got.Mean())
+	}
+
+	// Removing part of the weight of a value.
+	w := New()
+	w.AddN(1, 5)
+	w.AddN(100, 5)
+	got := w.Trimmed(0.2)
+	if got.Weight() != 6 || got.Mean() != 50.5 || got.Rank(1) != 3 {
+		t.Errorf("Trimmed(0.2) of weighted values: got weight %g, mean %g, rank of 1 %g; want 6, 50.5, 3",
+			got.Weight(), got.Mean(), got.Rank(1))
+	}
+
+	a := NewApprox(100)
+	for i := 1; i <= 1000; i++ {
+		a.Add(float64(i))
+	}
+	trimmed := a.Trimmed(0.1)
+	if !trimmed.Approx() {
+		t.Error("Trimmed of an approximate Summarizer is exact")
+	}
+	if math.Abs(trimmed.Min()-101) > 10 || math.Abs(trimmed.Max()-900) > 10 {
+		t.Errorf("Trimmed(0.1) of approximate 1..1000: got min %g, max %g; want about 101, 900", trimmed.Min(), trimmed.Max())
+	}
+}
+
+func TestWinsorized(t *testing.T) {
+	s := newTestSummarizer(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	got := s.Winsorized(0.2)
+	// The values become 3, 3, 3, 4, 5, 6, 7, 8, 8, 8.
+	if got.Count() != 10 || got.Min() != 3 || got.Max() != 8 || got.Mean() != 5.5 {
+		t.Errorf("Winsorized(0.2): got count %d, min %g, max %g, mean %g; want 10, 3, 8, 5.5",
+			got.Count(), got.Min(), got.Max(), got.Mean())
+	}
+	if got.Rank(3) != 3 {
+		t.Errorf("Winsorized(0.2): got rank of 3 %g; want 3", got.Rank(3))
+	}
+}
+
+func TestTrimmedPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Trimmed(0.5) did not panic")
+		}
+	}()
+	newTestSummarizer(1, 2).Trimmed(0.5)
+}
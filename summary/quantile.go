@@ -0,0 +1,175 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+)
+
+// A QuantileMethod selects how an exact Summarizer computes quantiles. Types
+// 1 through 9 are the sample quantile definitions described by Hyndman and
+// Fan in "Sample Quantiles in Statistical Packages" (1996), numbered as in
+// that paper and in R. In terms of the sorted values x[1], ..., x[n]:
+//
+//	Type1  the inverse of the empirical distribution function
+//	Type2  like Type1, but averaging at discontinuities
+//	Type3  the nearest value, with ties going to the even rank (SAS)
+//	Type4  linear interpolation of the empirical distribution function
+//	Type5  piecewise linear, with x[k] at (k-0.5)/n
+//	Type6  linear, with x[k] at k/(n+1) (Excel's PERCENTILE.EXC, Minitab)
+//	Type7  linear, with x[k] at (k-1)/(n-1) (R, NumPy, and Excel's PERCENTILE)
+//	Type8  approximately median-unbiased regardless of distribution
+//	Type9  approximately unbiased for normally distributed values
+//
+// The zero QuantileMethod, Nearest, is the default. It gives the value whose
+// rank is nearest to q*(n-1)+1, rounding halves up.
+//
+// Approximate Summarizers always interpolate between t-digest centroids and
+// ignore the QuantileMethod.
+type QuantileMethod int
+
+// The quantile methods.
+const (
+	Nearest QuantileMethod = iota
+	Type1
+	Type2
+	Type3
+	Type4
+	Type5
+	Type6
+	Type7
+	Type8
+	Type9
+
+	// Linear is the default method of R, NumPy, and Excel.
+	Linear = Type7
+)
+
+func (m QuantileMethod) String() string {
+	if m == Nearest {
+		return "nearest"
+	}
+	return fmt.Sprintf("type%d", int(m))
+}
+
+// SetQuantileMethod sets the method used by Quantile and Quantiles. It
+// panics if m is not one of the defined QuantileMethods.
+func (s *Summarizer) SetQuantileMethod(m QuantileMethod) {
+	if m < Nearest || m > Type9 {
+		panic("summary: invalid quantile method")
+	}
+	s.method = m
+}
+
+// QuantileMethod returns the method used by Quantile and Quantiles.
+func (s *Summarizer) QuantileMethod() QuantileMethod { return s.method }
+
+// quantileFuzz absorbs floating-point error in rank computations, as in R.
+const quantileFuzz = 4 * 2.220446049250313e-16
+
+// position gives the q-quantile of n sorted values in terms of their
+// (1-based) ranks: it is the value of rank j interpolated toward the value of
+// rank j+1 by the fraction h. Ranks outside [1, n] are clamped.
+func (m QuantileMethod) position(q, n float64) (j, h float64) {
+	var a, b float64
+	switch m {
+	case Nearest:
+		return math.Floor(q*(n-1)+0.5) + 1, 0
+	case Type1, Type2, Type3:
+		np := n * q
+		if m == Type3 {
+			np -= 0.5
+		}
+		j = math.Floor(np + quantileFuzz)
+		exact := np <= j // np is (nearly) a whole number
+		switch {
+		case m == Type1:
+			if !exact {
+				h = 1
+			}
+		case m == Type2:
+			h = 1
+			if exact {
+				h = 0.5
+			}
+		default:
+			if np != j || math.Mod(j, 2) != 0 {
+				h = 1
+			}
+		}
+		return j, h
+	case Type4:
+		a, b = 0, 1
+	case Type5:
+		a, b = 0.5, 0.5
+	case Type6:
+		a, b = 0, 0
+	case Type7:
+		a, b = 1, 1
+	case Type8:
+		a, b = 1.0/3, 1.0/3
+	case Type9:
+		a, b = 3.0/8, 3.0/8
+	}
+	p := a + q*(n+1-a-b)
+	j = math.Floor(p + quantileFuzz)
+	h = p - j
+	if math.Abs(h) < quantileFuzz {
+		h = 0
+	}
+	return j, h
+}
+
+// exactQuantiles computes the quantiles of an exact Summarizer into vs. Each
+// order statistic is found by selecting on the cumulative weights in the
+// tree, so the values aren't scanned.
+func (s *Summarizer) exactQuantiles(qs, vs []float64) {
+	unit := s.rankUnit()
+	n := s.weight / unit
+	stat := func(k float64) float64 { return s.orderStat(k, unit) }
+	for i, q := range qs {
+		vs[i] = interpolateOrderStats(s.method, q, n, stat)
+	}
+}
+
+// interpolateOrderStats computes the q-quantile of n values using method m,
+// given a function that returns the order statistic of each rank.
+func interpolateOrderStats(m QuantileMethod, q, n float64, stat func(k float64) float64) float64 {
+	j, h := m.position(q, n)
+	lo := stat(j)
+	if h == 0 {
+		return lo
+	}
+	hi := stat(j + 1)
+	if lo == hi {
+		return lo
+	}
+	return (1-h)*lo + h*hi
+}
+
+// rankUnit returns the weight of a single rank of an exact Summarizer.
+//
+// For weighted values, a value of weight w occupies w ranks and n is the total
+// weight. If any distinct value has a total weight less than 1, the ranks are
+// scaled so that the lightest value occupies a single rank.
+func (s *Summarizer) rankUnit() float64 {
+	unit := 1.0
+	if s.light {
+		s.walk(func(_, w float64) bool {
+			unit = math.Min(unit, w)
+			return true
+		})
+	}
+	return unit
+}
+
+// orderStat returns the order statistic of rank k of an exact Summarizer,
+// where each rank has the given weight: the first value whose cumulative
+// weight exceeds (k-1)*unit. Ranks beyond the last value (possible only due
+// to rounding or clamping) get the max.
+func (s *Summarizer) orderStat(k, unit float64) float64 {
+	v, _, ok := s.tree.Select((k - 1) * unit)
+	if !ok {
+		return s.max
+	}
+	return v
+}
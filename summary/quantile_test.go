@@ -0,0 +1,53 @@
+package summary
+
+import "testing"
+
+func TestQuantileMethods(t *testing.T) {
+	vs := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3}
+	qs := []float64{0, 0.1, 0.25, 0.5, 0.9, 1}
+	// Reference values for types 1-9 computed with a transcription of
+	// R's quantile function.
+	for _, tt := range []struct {
+		method QuantileMethod
+		want   []float64
+	}{
+		{Nearest, []float64{1, 1, 2, 4, 6, 9}},
+		{Type1, []float64{1, 1, 2, 3, 6, 9}},
+		{Type2, []float64{1, 1, 2, 3.5, 7.5, 9}},
+		{Type3, []float64{1, 1, 1, 3, 6, 9}},
+		{Type4, []float64{1, 1, 1.5, 3, 6, 9}},
+		{Type5, []float64{1, 1, 2, 3.5, 7.5, 9}},
+		{Type6, []float64{1, 1, 1.75, 3.5, 8.7, 9}},
+		{Type7, []float64{1, 1, 2.25, 3.5, 6.3, 9}},
+		{Type8, []float64{1, 1, 1.9166666666666665, 3.5, 7.9, 9}},
+		{Type9, []float64{1, 1, 1.9375, 3.5, 7.8, 9}},
+	} {
+		s := newTestSummarizer(vs...)
+		s.SetQuantileMethod(tt.method)
+		got := s.Quantiles(qs)
+		for i, q := range qs {
+			if !closeTo(got[i], tt.want[i]) {
+				t.Errorf("%s: Quantile(%g): got %g; want %g", tt.method, q, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestQuantileMethodsWeighted(t *testing.T) {
+	// Integer weights act like repeated values.
+	for m := Nearest; m <= Type9; m++ {
+		s0 := newTestSummarizer(1, 2, 2, 2, 3, 3, 7)
+		s1 := New()
+		s1.AddWeighted(3, 2)
+		s1.AddWeighted(1, 1)
+		s1.AddWeighted(7, 1)
+		s1.AddWeighted(2, 3)
+		s0.SetQuantileMethod(m)
+		s1.SetQuantileMethod(m)
+		for _, q := range []float64{0, 0.1, 0.3, 0.5, 0.75, 0.9, 1} {
+			if got, want := s1.Quantile(q), s0.Quantile(q); got != want {
+				t.Errorf("%s: Quantile(%g): got %g; want %g", m, q, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package summary
+
+import "math"
+
+// madScale scales the median absolute deviation to estimate the standard
+// deviation of normally distributed values.
+const madScale = 1.482602218505602 // 1/Φ⁻¹(3/4)
+
+// MAD returns the median absolute deviation: the median of the absolute
+// differences between the values and their median, both computed using the
+// QuantileMethod. See also ScaledMAD. MAD returns NaN if s is empty. For an
+// approximate Summarizer, the result is an estimate.
+func (s *Summarizer) MAD() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	med := s.Quantile(0.5)
+	devs := New()
+	devs.method = s.method
+	devs.count = s.count
+	s.walk(func(v, w float64) bool {
+		devs.add(math.Abs(v-med), w)
+		return true
+	})
+	return devs.Quantile(0.5)
+}
+
+// ScaledMAD returns the median absolute deviation scaled by 1.4826, which
+// makes it an estimate of the standard deviation of normally distributed
+// values that, unlike StdDev, is barely affected by outliers.
+func (s *Summarizer) ScaledMAD() float64 {
+	return madScale * s.MAD()
+}
+
+// IQR returns the interquartile range: the difference between the 0.75
+// and 0.25 quantiles. It returns NaN if s is empty.
+func (s *Summarizer) IQR() float64 {
+	qs := s.Quantiles([]float64{0.25, 0.75})
+	return qs[1] - qs[0]
+}
+
+// TrimmedMean returns the mean of the values without the lowest and
+// highest fraction p of them, as for Trimmed. It returns NaN if s is empty
+// and panics if p is outside [0, 0.5).
+func (s *Summarizer) TrimmedMean(p float64) float64 {
+	return s.Trimmed(p).Mean()
+}
+
+// GeometricMean returns the geometric mean of the values, the nth root of
+// their product. It returns NaN if s is empty or any value is not positive.
+// For an approximate Summarizer, the result is an estimate.
+func (s *Summarizer) GeometricMean() float64 {
+	if s.count == 0 || !(s.min > 0) {
+		return math.NaN()
+	}
+	var sum float64
+	s.walk(func(v, w float64) bool {
+		sum += w * math.Log(v)
+		return true
+	})
+	return math.Exp(sum / s.weight)
+}
+
+// HarmonicMean returns the harmonic mean of the values, the reciprocal of
+// the mean of their reciprocals. It returns NaN if s is empty or any value
+// is not positive. For an approximate Summarizer, the result is an estimate.
+func (s *Summarizer) HarmonicMean() float64 {
+	if s.count == 0 || !(s.min > 0) {
+		return math.NaN()
+	}
+	var sum float64
+	s.walk(func(v, w float64) bool {
+		sum += w / v
+		return true
+	})
+	return s.weight / sum
+}
+
+// Modes returns the most common values, in increasing order, along with
+// their total weight (for unweighted values, the number of times each one
+// occurs). There is more than one mode if several values tie. Modes returns
+// nil and NaN if s is empty or approximate, since a t-digest doesn't keep
+// the distinct values.
+func (s *Summarizer) Modes() (modes []float64, weight float64) {
+	if s.count == 0 || s.digest != nil {
+		return nil, math.NaN()
+	}
+	s.walk(func(v, w float64) bool {
+		switch {
+		case w > weight:
+			modes = append(modes[:0], v)
+			weight = w
+		case w == weight:
+			modes = append(modes, v)
+		}
+		return true
+	})
+	return modes, weight
+}
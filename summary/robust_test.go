@@ -0,0 +1,66 @@
+package summary
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMAD(t *testing.T) {
+	// The median is 2 and the sorted deviations are 0, 0, 1, 1, 2, 4, 7.
+	s := newTestSummarizer(1, 1, 2, 2, 4, 6, 9)
+	if got := s.MAD(); got != 1 {
+		t.Errorf("MAD: got %g; want 1", got)
+	}
+	if got := New().MAD(); !math.IsNaN(got) {
+		t.Errorf("MAD of empty Summarizer: got %g; want NaN", got)
+	}
+}
+
+func TestRobustStats(t *testing.T) {
+	s := newTestSummarizer(1, 1, 2, 2, 4, 6, 9)
+	if got, want := s.ScaledMAD(), 1.482602218505602; got != want {
+		t.Errorf("ScaledMAD: got %g; want %g", got, want)
+	}
+	// The quartiles (nearest rank) are the 3rd and 6th values.
+	if got := s.IQR(); got != 4 {
+		t.Errorf("IQR: got %g; want 4", got)
+	}
+	// Trimming 0.2 of 7 values removes one from each end: 1, 2, 2, 4, 6.
+	if got := s.TrimmedMean(0.2); got != 3 {
+		t.Errorf("TrimmedMean(0.2): got %g; want 3", got)
+	}
+	modes, weight := s.Modes()
+	if want := []float64{1, 2}; !reflect.DeepEqual(modes, want) || weight != 2 {
+		t.Errorf("Modes: got %v (weight %g); want %v (weight 2)", modes, weight, want)
+	}
+
+	s = newTestSummarizer(1, 2, 4, 8)
+	if got := s.GeometricMean(); math.Abs(got-math.Pow(64, 0.25)) > 1e-12 {
+		t.Errorf("GeometricMean: got %g; want %g", got, math.Pow(64, 0.25))
+	}
+	if got, want := s.HarmonicMean(), 4/(1+0.5+0.25+0.125); math.Abs(got-want) > 1e-12 {
+		t.Errorf("HarmonicMean: got %g; want %g", got, want)
+	}
+
+	s = newTestSummarizer(0, 1, 2)
+	if got := s.GeometricMean(); !math.IsNaN(got) {
+		t.Errorf("GeometricMean with a zero: got %g; want NaN", got)
+	}
+	if got := s.HarmonicMean(); !math.IsNaN(got) {
+		t.Errorf("HarmonicMean with a zero: got %g; want NaN", got)
+	}
+
+	w := New()
+	w.AddWeighted(3, 2.5)
+	w.AddWeighted(5, 0.5)
+	if modes, weight := w.Modes(); !reflect.DeepEqual(modes, []float64{3}) || weight != 2.5 {
+		t.Errorf("Modes of weighted values: got %v (weight %g); want [3] (weight 2.5)", modes, weight)
+	}
+
+	a := NewApprox(100)
+	a.Add(1)
+	if modes, weight := a.Modes(); modes != nil || !math.IsNaN(weight) {
+		t.Errorf("Modes of approximate Summarizer: got %v (weight %g); want nil (NaN)", modes, weight)
+	}
+}
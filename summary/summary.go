@@ -0,0 +1,314 @@
+// Package summary computes summary statistics over a sequence of numbers.
+//
+// A Summarizer created by New records each distinct value it is given along
+// with its total weight (the number of times it was seen, for unweighted
+// values), so the quantiles and histograms it reports are exact. Memory use
+// grows with the number of distinct values.
+//
+// A Summarizer created by NewApprox instead keeps a t-digest, a sketch of the
+// distribution whose size is bounded regardless of the number of values.
+// Quantiles and histograms are then estimates, but the count, min, max, mean,
+// and standard deviation remain exact (up to floating-point rounding).
+//
+// Values may be given weights with AddWeighted, which need not be whole
+// numbers. Counts of values, such as Weight, Rank, and the Count, Underflow,
+// and Overflow of a Histogram, are then total weights.
+package summary
+
+import (
+	"io"
+	"math"
+
+	"github.com/cespare/stats/internal/b"
+)
+
+// A Summarizer accumulates numbers and reports statistics about them.
+// Create Summarizers with New or NewApprox.
+type Summarizer struct {
+	tree   *b.Tree // nil if approximate
+	digest *digest // nil if exact
+	count  int64
+	weight float64 // the total weight; equal to count if unweighted
+	min    float64
+	max    float64
+
+	// For an exact Summarizer, the moments are computed by walking the
+	// tree in order so that the results don't depend on the order in which
+	// values were added; stale reports whether they need to be recomputed.
+	// An approximate Summarizer updates them as values are added.
+	stale bool
+	m     moments
+
+	// light reports whether some distinct value may have a total weight
+	// less than 1, which affects how exact quantiles are computed.
+	light bool
+
+	method QuantileMethod
+}
+
+// New returns an empty Summarizer that computes exact quantiles.
+func New() *Summarizer {
+	return &Summarizer{tree: b.TreeNew(cmpFloat)}
+}
+
+// NewApprox returns an empty Summarizer that estimates quantiles using a
+// t-digest with the given compression. Higher compression gives more
+// accurate estimates and uses more memory: the digest holds on the order of
+// compression centroids. A compression of 100 is a reasonable default. It
+// panics if compression is less than 20.
+func NewApprox(compression float64) *Summarizer {
+	if compression < 20 {
+		panic("summary: t-digest compression must be at least 20")
+	}
+	return &Summarizer{digest: newDigest(compression)}
+}
+
+// Approx reports whether s estimates quantiles rather than computing them
+// exactly.
+func (s *Summarizer) Approx() bool { return s.digest != nil }
+
+// Compression returns the t-digest compression of an approximate Summarizer,
+// or 0 if s is exact.
+func (s *Summarizer) Compression() float64 {
+	if s.digest == nil {
+		return 0
+	}
+	return s.digest.compression
+}
+
+func cmpFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+// Add records the value v.
+func (s *Summarizer) Add(v float64) {
+	s.AddN(v, 1)
+}
+
+// AddN records n occurrences of the value v. It panics if n is negative.
+func (s *Summarizer) AddN(v float64, n int64) {
+	if n < 0 {
+		panic("summary: negative count given to AddN")
+	}
+	s.count += n
+	s.add(v, float64(n))
+}
+
+// AddWeighted records the value v with weight w, which counts as a single
+// value for Count but as w values for every other statistic. For example,
+// AddWeighted(v, 3) affects the mean, quantiles, and histograms in the same
+// way as AddN(v, 3). It panics if w is negative, infinite, or NaN.
+func (s *Summarizer) AddWeighted(v, w float64) {
+	if !(w >= 0) || math.IsInf(w, 1) {
+		panic("summary: invalid weight given to AddWeighted")
+	}
+	if w == 0 {
+		return
+	}
+	s.count++
+	s.add(v, w)
+}
+
+// add records v with weight w without changing the count.
+func (s *Summarizer) add(v, w float64) {
+	if w == 0 {
+		return
+	}
+	if s.weight == 0 || v < s.min {
+		s.min = v
+	}
+	if s.weight == 0 || v > s.max {
+		s.max = v
+	}
+	s.weight += w
+	if s.digest != nil {
+		s.digest.add(v, w)
+		s.m.add(v, w)
+		return
+	}
+	s.tree.Put(v, func(old float64, _ bool) (float64, bool) {
+		if old+w < 1 {
+			s.light = true
+		}
+		return old + w, true
+	})
+	s.stale = true
+}
+
+// Count returns the number of values that have been added.
+func (s *Summarizer) Count() int64 { return s.count }
+
+// Weight returns the total weight of the values that have been added. If all
+// the values were added with Add or AddN, it is the same as Count.
+func (s *Summarizer) Weight() float64 { return s.weight }
+
+// Min returns the smallest value added, or NaN if s is empty.
+func (s *Summarizer) Min() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.min
+}
+
+// Max returns the largest value added, or NaN if s is empty.
+func (s *Summarizer) Max() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.max
+}
+
+// Mean returns the weighted arithmetic mean of the values, or NaN if s is
+// empty.
+func (s *Summarizer) Mean() float64 {
+	s.computeMoments()
+	if s.m.n == 0 {
+		return math.NaN()
+	}
+	return s.m.trueMean()
+}
+
+// StdDev returns the weighted population standard deviation of the values,
+// or NaN if s is empty.
+func (s *Summarizer) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// Variance returns the weighted population variance of the values, or NaN
+// if s is empty.
+func (s *Summarizer) Variance() float64 {
+	s.computeMoments()
+	return s.m.variance()
+}
+
+// SampleVariance returns the unbiased estimate of the variance of the
+// distribution the values were drawn from, treating weights as frequencies.
+// It is NaN unless the total weight is greater than 1.
+func (s *Summarizer) SampleVariance() float64 {
+	s.computeMoments()
+	if s.m.n <= 1 {
+		return math.NaN()
+	}
+	return s.m.sampleVariance()
+}
+
+// Skewness returns the population skewness of the values, a measure of the
+// asymmetry of their distribution: it is positive if the distribution has a
+// long right tail and negative if it has a long left tail. It is NaN if s is
+// empty or all the values are equal.
+func (s *Summarizer) Skewness() float64 {
+	s.computeMoments()
+	return s.m.skewness()
+}
+
+// Kurtosis returns the population excess kurtosis of the values, a measure
+// of the weight of the tails of their distribution relative to a normal
+// distribution (for which it is 0). It is NaN if s is empty or all the
+// values are equal.
+func (s *Summarizer) Kurtosis() float64 {
+	s.computeMoments()
+	return s.m.kurtosis()
+}
+
+// CV returns the coefficient of variation of the values: the ratio of the
+// population standard deviation to the mean.
+func (s *Summarizer) CV() float64 {
+	return s.StdDev() / s.Mean()
+}
+
+// StdErr returns the standard error of the mean: the sample standard
+// deviation divided by the square root of the total weight.
+func (s *Summarizer) StdErr() float64 {
+	return math.Sqrt(s.SampleVariance() / s.weight)
+}
+
+func (s *Summarizer) computeMoments() {
+	if !s.stale {
+		return
+	}
+	s.m = exactMoments(s.walk)
+	s.stale = false
+}
+
+// Quantile returns the q-quantile of the values (for example, q = 0.9 gives
+// the 90th percentile). For an exact Summarizer, the result depends on the
+// QuantileMethod; by default, it is the value whose rank is nearest to
+// q*(n-1) where n is the count. An approximate Summarizer interpolates
+// between t-digest centroids. Quantile panics if q is outside [0, 1] and
+// returns NaN if s is empty.
+func (s *Summarizer) Quantile(q float64) float64 {
+	return s.Quantiles([]float64{q})[0]
+}
+
+// Quantiles is like Quantile but computes several quantiles at once. The
+// results are in the same order as qs.
+func (s *Summarizer) Quantiles(qs []float64) []float64 {
+	vs := make([]float64, len(qs))
+	if s.count == 0 {
+		for i := range vs {
+			vs[i] = math.NaN()
+		}
+		return vs
+	}
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			panic("summary: quantile out of range [0, 1]")
+		}
+	}
+	if s.digest != nil {
+		for i, q := range qs {
+			vs[i], _ = s.digest.quantile(q, s.min, s.max)
+		}
+		return vs
+	}
+	s.exactQuantiles(qs, vs)
+	return vs
+}
+
+// RankError returns an estimate of the error of Quantile(q), in terms of
+// rank, as a fraction of the count. For example, if RankError(0.99) is 0.001,
+// the value returned by Quantile(0.99) is likely between the 98.9th and
+// 99.1st percentiles. The result is always 0 for an exact Summarizer.
+func (s *Summarizer) RankError(q float64) float64 {
+	if q < 0 || q > 1 {
+		panic("summary: quantile out of range [0, 1]")
+	}
+	if s.digest == nil {
+		return 0
+	}
+	_, err := s.digest.quantile(q, s.min, s.max)
+	return err
+}
+
+// walk calls fn for each distinct value, in increasing order, along with its
+// total weight. For an approximate Summarizer, walk reports the t-digest
+// centroids instead. If fn returns false, walk stops.
+func (s *Summarizer) walk(fn func(v, w float64) bool) {
+	if s.digest != nil {
+		s.digest.each(fn)
+		return
+	}
+	it, err := s.tree.SeekFirst()
+	if err == io.EOF {
+		return
+	}
+	defer it.Close()
+	for {
+		v, w, err := it.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			panic(err)
+		}
+		if !fn(v, w) {
+			return
+		}
+	}
+}
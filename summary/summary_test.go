@@ -0,0 +1,150 @@
+package summary
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func newTestSummarizer(vs ...float64) *Summarizer {
+	s := New()
+	for _, v := range vs {
+		s.Add(v)
+	}
+	return s
+}
+
+func TestBasicStats(t *testing.T) {
+	s := newTestSummarizer(111, 105, 107, 93, 99, 104)
+	if got, want := s.Count(), int64(6); got != want {
+		t.Errorf("Count: got %d; want %d", got, want)
+	}
+	if got, want := s.Min(), 93.0; got != want {
+		t.Errorf("Min: got %g; want %g", got, want)
+	}
+	if got, want := s.Max(), 111.0; got != want {
+		t.Errorf("Max: got %g; want %g", got, want)
+	}
+	if got, want := s.Mean(), 103.16666666666667; !closeTo(got, want) {
+		t.Errorf("Mean: got %g; want %g", got, want)
+	}
+	if got, want := s.StdDev(), 5.785518319236594; !closeTo(got, want) {
+		t.Errorf("StdDev: got %g; want %g", got, want)
+	}
+}
+
+func TestAddN(t *testing.T) {
+	s0 := newTestSummarizer(1, 2, 2, 2, 3, 3)
+	s1 := New()
+	s1.AddN(3, 2)
+	s1.AddN(1, 1)
+	s1.AddN(5, 0)
+	s1.AddN(2, 3)
+	for _, check := range []struct {
+		name string
+		f    func(*Summarizer) float64
+	}{
+		{"Count", func(s *Summarizer) float64 { return float64(s.Count()) }},
+		{"Min", (*Summarizer).Min},
+		{"Max", (*Summarizer).Max},
+		{"Mean", (*Summarizer).Mean},
+		{"StdDev", (*Summarizer).StdDev},
+		{"Quantile(0.5)", func(s *Summarizer) float64 { return s.Quantile(0.5) }},
+	} {
+		if got, want := check.f(s1), check.f(s0); got != want {
+			t.Errorf("%s: got %g; want %g", check.name, got, want)
+		}
+	}
+}
+
+func TestAddWeighted(t *testing.T) {
+	s0 := newTestSummarizer(1, 2, 2, 2, 3, 3)
+	s1 := New()
+	s1.AddWeighted(3, 2)
+	s1.AddWeighted(1, 1)
+	s1.AddWeighted(5, 0)
+	s1.AddWeighted(2, 3)
+	if got, want := s1.Count(), int64(3); got != want {
+		t.Errorf("Count: got %d; want %d", got, want)
+	}
+	if got, want := s1.Weight(), 6.0; got != want {
+		t.Errorf("Weight: got %g; want %g", got, want)
+	}
+	for _, check := range []struct {
+		name string
+		f    func(*Summarizer) float64
+	}{
+		{"Min", (*Summarizer).Min},
+		{"Max", (*Summarizer).Max},
+		{"Mean", (*Summarizer).Mean},
+		{"StdDev", (*Summarizer).StdDev},
+		{"Quantile(0.5)", func(s *Summarizer) float64 { return s.Quantile(0.5) }},
+	} {
+		if got, want := check.f(s1), check.f(s0); got != want {
+			t.Errorf("%s: got %g; want %g", check.name, got, want)
+		}
+	}
+
+	s := New()
+	s.AddWeighted(10, 0.25)
+	s.AddWeighted(20, 0.5)
+	s.AddWeighted(40, 0.25)
+	if got, want := s.Mean(), 22.5; !closeTo(got, want) {
+		t.Errorf("Mean: got %g; want %g", got, want)
+	}
+	if got, want := s.StdDev(), 10.897247358851684; !closeTo(got, want) {
+		t.Errorf("StdDev: got %g; want %g", got, want)
+	}
+	qs := []float64{0, 0.5, 1}
+	if got, want := s.Quantiles(qs), []float64{10, 20, 40}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Quantiles(%v): got %v; want %v", qs, got, want)
+	}
+	h := s.Histogram(3)
+	var counts []float64
+	for _, b := range h.Buckets {
+		counts = append(counts, b.Count)
+	}
+	if want := []float64{0.25, 0.5, 0.25}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("histogram counts: got %v; want %v", counts, want)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	s := New()
+	for _, check := range []struct {
+		name string
+		v    float64
+	}{
+		{"Min", s.Min()},
+		{"Max", s.Max()},
+		{"Mean", s.Mean()},
+		{"StdDev", s.StdDev()},
+		{"Quantile(0.5)", s.Quantile(0.5)},
+	} {
+		if !math.IsNaN(check.v) {
+			t.Errorf("%s of empty Summarizer: got %g; want NaN", check.name, check.v)
+		}
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	s := New()
+	for i := 1; i <= 100; i++ {
+		s.Add(float64(i))
+	}
+	qs := []float64{0.99, 0, 0.5, 1, 0.9}
+	got := s.Quantiles(qs)
+	want := []float64{99, 1, 51, 100, 90}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Quantiles(%v): got %v; want %v", qs, got, want)
+	}
+	for i, q := range qs {
+		if got := s.Quantile(q); got != want[i] {
+			t.Errorf("Quantile(%g): got %g; want %g", q, got, want[i])
+		}
+	}
+}
+
+func closeTo(x, y float64) bool {
+	return math.Abs(x-y) <= 1e-9*math.Max(math.Abs(x), math.Abs(y))
+}
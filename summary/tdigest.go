@@ -0,0 +1,173 @@
+package summary
+
+import (
+	"math"
+	"sort"
+)
+
+// A digest is a merging t-digest, a sketch of a distribution that uses
+// bounded memory and gives accurate estimates of extreme quantiles. See
+// "Computing Extremely Accurate Quantiles Using t-Digests" by Dunning and
+// Ertl (https://arxiv.org/abs/1902.04023).
+//
+// This implementation uses the k1 scale function, which keeps centroids
+// near the tails of the distribution small.
+type digest struct {
+	compression float64
+	centroids   []centroid // merged centroids, sorted by mean
+	weight      float64    // total weight of centroids
+	buf         []centroid // unmerged values
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+func newDigest(compression float64) *digest {
+	return &digest{
+		compression: compression,
+		buf:         make([]centroid, 0, digestBufferSize(compression)),
+	}
+}
+
+func digestBufferSize(compression float64) int {
+	n := int(5 * compression)
+	if n < 100 {
+		n = 100
+	}
+	return n
+}
+
+func (d *digest) add(v, w float64) {
+	d.buf = append(d.buf, centroid{v, w})
+	if len(d.buf) == cap(d.buf) {
+		d.compress()
+	}
+}
+
+// compress merges the buffered values into the centroids.
+func (d *digest) compress() {
+	if len(d.buf) == 0 {
+		return
+	}
+	all := append(d.buf, d.centroids...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	var total float64
+	for _, c := range all {
+		total += c.weight
+	}
+
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	cur := all[0]
+	var soFar float64 // weight of the centroids before cur
+	limit := d.qLimit(0)
+	for _, c := range all[1:] {
+		if (soFar+cur.weight+c.weight)/total <= limit {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		soFar += cur.weight
+		merged = append(merged, cur)
+		limit = d.qLimit(soFar / total)
+		cur = c
+	}
+	merged = append(merged, cur)
+
+	d.centroids = merged
+	d.weight = total
+	d.buf = d.buf[:0]
+}
+
+// qLimit gives the largest quantile that a centroid starting at quantile q
+// may extend to: the point where the k1 scale function,
+//
+//	k(q) = δ/2π · asin(2q - 1),
+//
+// has increased by 1.
+func (d *digest) qLimit(q float64) float64 {
+	k := d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+	if k+1 >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin((k+1)*2*math.Pi/d.compression) + 1) / 2
+}
+
+// quantile estimates the q-quantile by interpolating between the centers of
+// adjacent centroids, where the center of a centroid is the cumulative
+// weight at its midpoint. The min and max anchor either end.
+//
+// It also returns an estimate of the error of the result, in terms of rank,
+// as a fraction of the total weight: half the combined weight of the
+// centroids on either side of the estimate.
+func (d *digest) quantile(q, min, max float64) (v, rankErr float64) {
+	d.compress()
+	cs := d.centroids
+	if len(cs) == 0 {
+		return math.NaN(), 0
+	}
+	n := d.weight
+	target := q * n
+	if target <= cs[0].weight/2 {
+		return interpolate(target, 0, cs[0].weight/2, min, cs[0].mean), cs[0].weight / (2 * n)
+	}
+	var cum float64
+	for i := 0; i < len(cs)-1; i++ {
+		left := cum + cs[i].weight/2
+		right := cum + cs[i].weight + cs[i+1].weight/2
+		if target <= right {
+			v := interpolate(target, left, right, cs[i].mean, cs[i+1].mean)
+			return v, (cs[i].weight + cs[i+1].weight) / (2 * n)
+		}
+		cum += cs[i].weight
+	}
+	last := cs[len(cs)-1]
+	return interpolate(target, n-last.weight/2, n, last.mean, max), last.weight / (2 * n)
+}
+
+// rank estimates the total weight of the values less than or equal to x. It
+// is the inverse of quantile: it interpolates between the same points.
+func (d *digest) rank(x, min, max float64) float64 {
+	d.compress()
+	cs := d.centroids
+	n := d.weight
+	switch {
+	case len(cs) == 0 || x < min:
+		return 0
+	case x >= max:
+		return n
+	case x < cs[0].mean:
+		return interpolate(x, min, cs[0].mean, 0, cs[0].weight/2)
+	}
+	var cum float64
+	for i := 0; i < len(cs)-1; i++ {
+		if x < cs[i+1].mean {
+			left := cum + cs[i].weight/2
+			right := cum + cs[i].weight + cs[i+1].weight/2
+			return interpolate(x, cs[i].mean, cs[i+1].mean, left, right)
+		}
+		cum += cs[i].weight
+	}
+	last := cs[len(cs)-1]
+	return interpolate(x, last.mean, max, n-last.weight/2, n)
+}
+
+// interpolate linearly maps x in [x0, x1] to [y0, y1].
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		return y0
+	}
+	return y0 + (x-x0)/(x1-x0)*(y1-y0)
+}
+
+// each calls fn for each centroid in order of increasing mean. If fn
+// returns false, each stops.
+func (d *digest) each(fn func(mean, weight float64) bool) {
+	d.compress()
+	for _, c := range d.centroids {
+		if !fn(c.mean, c.weight) {
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestApproxQuantiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	s := NewApprox(100)
+	var vs []float64
+	for i := 0; i < 100000; i++ {
+		v := math.Exp(rng.NormFloat64()) // long-tailed, like latencies
+		vs = append(vs, v)
+		s.Add(v)
+	}
+	sort.Float64s(vs)
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		got := s.Quantile(q)
+		// Find the rank of the estimate and compare with the requested
+		// quantile.
+		rank := float64(sort.SearchFloat64s(vs, got)) / float64(len(vs))
+		bound := s.RankError(q)
+		if bound <= 0 {
+			t.Errorf("RankError(%g) = %g; want > 0", q, bound)
+		}
+		if math.Abs(rank-q) > 2*bound+1e-4 {
+			t.Errorf("Quantile(%g) = %g, which has rank %g; RankError is %g", q, got, rank, bound)
+		}
+	}
+	if got, want := s.Count(), int64(len(vs)); got != want {
+		t.Errorf("Count: got %d; want %d", got, want)
+	}
+	if got, want := s.Min(), vs[0]; got != want {
+		t.Errorf("Min: got %g; want %g", got, want)
+	}
+	if got, want := s.Max(), vs[len(vs)-1]; got != want {
+		t.Errorf("Max: got %g; want %g", got, want)
+	}
+}
+
+func TestApproxBoundedSize(t *testing.T) {
+	s := NewApprox(50)
+	for i := 0; i < 1e6; i++ {
+		s.Add(float64(i))
+	}
+	s.digest.compress()
+	if n := len(s.digest.centroids); n > 100 {
+		t.Errorf("digest with compression 50 has %d centroids", n)
+	}
+	if got := s.Quantile(0.5); math.Abs(got-5e5) > 1e4 {
+		t.Errorf("Quantile(0.5): got %g; want about 5e5", got)
+	}
+}
+
+func TestApproxSmall(t *testing.T) {
+	s := NewApprox(100)
+	for _, v := range []float64{111, 105, 107, 93, 99, 104} {
+		s.Add(v)
+	}
+	if got, want := s.Mean(), 103.16666666666667; !closeTo(got, want) {
+		t.Errorf("Mean: got %g; want %g", got, want)
+	}
+	if got, want := s.StdDev(), 5.785518319236594; !closeTo(got, want) {
+		t.Errorf("StdDev: got %g; want %g", got, want)
+	}
+	if got := s.Quantile(0); got != 93 {
+		t.Errorf("Quantile(0): got %g; want 93", got)
+	}
+	if got := s.Quantile(1); got != 111 {
+		t.Errorf("Quantile(1): got %g; want 111", got)
+	}
+	var total float64
+	for _, b := range s.Histogram(3).Buckets {
+		total += b.Count
+	}
+	if total != 6 {
+		t.Errorf("histogram total count is %g; want 6", total)
+	}
+}
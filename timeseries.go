@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/stats/summary"
+)
+
+func timeseries(args []string) {
+	fs := flag.NewFlagSet("timeseries", flag.ExitOnError)
+	quantStr := fs.String("quantiles", "0.5,0.9,0.99", "Quantiles to record")
+	methodStr := fs.String("quantile-method", "nearest", quantileMethodUsage)
+	formatStr := fs.String("format", "table", "Output format: table, json, csv, or tsv")
+	inOpts := addInputFlags(fs)
+	fs.StringVar(&inOpts.key, "time", "1", "Read timestamps from this field")
+	timeFormat := fs.String("time-format", "rfc3339", "Timestamp format: rfc3339, unix, unixms, or a Go time layout such as '2006-01-02 15:04:05'")
+	bucket := fs.Duration("bucket", time.Minute, "Length of each time window")
+	approx := fs.Bool("approx", false, "Estimate quantiles using a t-digest, which uses bounded memory")
+	compression := fs.Float64("compression", 100, "With -approx, the t-digest compression (higher is more accurate)")
+	fs.Parse(args)
+
+	format, err := parseFormat(*formatStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	quants, err := parseQuantiles(*quantStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	method, err := parseQuantileMethod(*methodStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *bucket <= 0 {
+		log.Fatalf("-bucket must be positive; got %s", *bucket)
+	}
+	if *approx && *compression < 20 {
+		log.Fatalf("compression must be at least 20; got %g", *compression)
+	}
+	newSummarizer := func() *summary.Summarizer {
+		sr := summary.New()
+		if *approx {
+			sr = summary.NewApprox(*compression)
+		}
+		sr.SetQuantileMethod(method)
+		return sr
+	}
+	parse, err := timeParser(*timeFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if inOpts.re != nil && inOpts.key == "1" && inOpts.hasGroup("time") {
+		inOpts.key = "time"
+	}
+	if inOpts.field == "" && !inOpts.hasGroup("value") {
+		inOpts.field = "2"
+	}
+
+	nr, err := newNumberReader(inOpts, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	windows := make(map[time.Time]*summary.Summarizer)
+	var badTime int64
+	for nr.Scan() {
+		t, err := parse(strings.TrimSpace(nr.Key()))
+		if err != nil {
+			badTime++
+			continue
+		}
+		start := t.UTC().Truncate(*bucket)
+		sr, ok := windows[start]
+		if !ok {
+			sr = newSummarizer()
+			windows[start] = sr
+		}
+		sr.AddWeighted(nr.Value(), nr.Weight())
+	}
+	if err := nr.Err(); err != nil {
+		log.Fatal(err)
+	}
+	nr.warn()
+	if badTime > 0 {
+		log.Printf("warning: found %d records with an invalid timestamp in field %s", badTime, inOpts.key)
+	}
+	if len(windows) == 0 {
+		log.Println("no numbers given")
+		return
+	}
+	if err := writeRows(os.Stdout, format, windowRows(windows, quants, nr.Unit())); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// timeParser returns a function that parses timestamps in the given format.
+func timeParser(format string) (func(string) (time.Time, error), error) {
+	switch format {
+	case "":
+		return nil, errors.New("empty time format")
+	case "rfc3339":
+		// Fractional seconds are accepted even if not present in the layout.
+		return func(s string) (time.Time, error) { return time.Parse(time.RFC3339, s) }, nil
+	case "unix":
+		return func(s string) (time.Time, error) { return parseUnix(s, 1e9) }, nil
+	case "unixms":
+		return func(s string) (time.Time, error) { return parseUnix(s, 1e6) }, nil
+	}
+	return func(s string) (time.Time, error) { return time.Parse(format, s) }, nil
+}
+
+// parseUnix parses a (possibly fractional) number of units since the Unix
+// epoch, where scale is the number of nanoseconds in a unit. Times that
+// can't be represented in nanoseconds since the epoch (outside the years
+// 1678 to 2262), such as milliseconds read as seconds, are invalid.
+func parseUnix(s string, scale float64) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > math.MaxInt64/int64(scale) || n < math.MinInt64/int64(scale) {
+			return time.Time{}, fmt.Errorf("timestamp %q out of range", s)
+		}
+		return time.Unix(0, n*int64(scale)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	if ns := f * scale; ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return time.Time{}, fmt.Errorf("timestamp %q out of range", s)
+	}
+	sec, frac := math.Modf(f * scale / 1e9)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+// windowRows computes a row of stats for each time window, prefixed by the
+// start of the window, in chronological order. Values are given in the unit
+// u.
+func windowRows(windows map[time.Time]*summary.Summarizer, quants []float64, u unit) [][]stat {
+	starts := make([]time.Time, 0, len(windows))
+	for t := range windows {
+		starts = append(starts, t)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	rows := make([][]stat, len(starts))
+	for i, t := range starts {
+		start := stat{"start", "start", t.Format(time.RFC3339)}
+		rows[i] = append([]stat{start}, summaryStats(windows[t], quants, false, u)...)
+	}
+	return rows
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUnix(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		scale float64
+		want  time.Time // zero for an error
+	}{
+		{"1700000000", 1e9, time.Unix(1700000000, 0)},
+		{"1.5", 1e9, time.Unix(1, 5e8)},
+		{"1700000000123", 1e6, time.Unix(1700000000, 123e6)},
+		{"-86400", 1e9, time.Unix(-86400, 0)},
+		// Milliseconds read as seconds overflow nanoseconds since the
+		// epoch.
+		{"1700000000123", 1e9, time.Time{}},
+		{"-1700000000123", 1e9, time.Time{}},
+		{"1700000000123.5", 1e9, time.Time{}},
+		{"NaN", 1e9, time.Time{}},
+		{"x", 1e9, time.Time{}},
+	} {
+		got, err := parseUnix(tt.s, tt.scale)
+		if tt.want.IsZero() {
+			if err == nil {
+				t.Errorf("parseUnix(%q, %g): got %s; want error", tt.s, tt.scale, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseUnix(%q, %g): got %s, %v; want %s", tt.s, tt.scale, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A unit is a kind of quantity that numbers may be written in, using
+// suffixes such as "ms" or "KiB". Values are converted to a base unit
+// (seconds, bytes, or plain numbers) when they are read.
+type unit int
+
+const (
+	unitNone     unit = iota // plain numbers
+	unitDuration             // seconds, written like 12.5ms or 1h30m
+	unitBytes                // bytes, written like 340KiB or 1.5GB
+	unitSI                   // plain numbers with SI prefixes, like 3k or 2.5M
+)
+
+const unitUsage = "Parse numbers written with units: duration (12.5ms, 1h30m), bytes (340KiB, 1.5GB), " +
+	"si (3k, 2.5M), or auto (whichever the input uses); values are printed back in the same unit"
+
+// A unitParser parses numbers in a unit. In auto mode, the unit is that of
+// the first number with a suffix; after that, only numbers in the same unit
+// (or without a suffix) are accepted.
+type unitParser struct {
+	unit unit
+	auto bool
+}
+
+func newUnitParser(s string) (*unitParser, error) {
+	switch s {
+	case "", "none":
+		return &unitParser{unit: unitNone}, nil
+	case "duration":
+		return &unitParser{unit: unitDuration}, nil
+	case "bytes":
+		return &unitParser{unit: unitBytes}, nil
+	case "si":
+		return &unitParser{unit: unitSI}, nil
+	case "auto":
+		return &unitParser{auto: true}, nil
+	}
+	return nil, fmt.Errorf("unknown unit %q (must be duration, bytes, si, or auto)", s)
+}
+
+func (p *unitParser) parse(s string) (float64, error) {
+	if !p.auto || p.unit != unitNone {
+		return p.unit.parse(s)
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+	for _, u := range []unit{unitDuration, unitBytes, unitSI} {
+		if v, err := u.parse(s); err == nil {
+			p.unit = u
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid number %q", s)
+}
+
+// parseValue parses a threshold or bound given on the command line in the
+// unit of the input, as given by units. With -unit auto, it may have any
+// unit's suffix.
+func parseValue(s string, units *unitParser) (float64, error) {
+	p := *units
+	return p.parse(strings.TrimSpace(s))
+}
+
+// parse parses s as a number in the unit u, converting it to the base unit.
+// Numbers without a suffix are taken to be in the base unit already.
+func (u unit) parse(s string) (float64, error) {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+	switch u {
+	case unitDuration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		return d.Seconds(), nil
+	case unitBytes:
+		return parseSuffixed(s, byteSuffixes)
+	case unitSI:
+		return parseSuffixed(s, siSuffixes)
+	}
+	return 0, fmt.Errorf("invalid number %q", s)
+}
+
+var byteSuffixes = map[string]float64{
+	"B":   1,
+	"kB":  1e3,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"EB":  1e18,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"PiB": 1 << 50,
+	"EiB": 1 << 60,
+}
+
+var siSuffixes = map[string]float64{
+	"p": 1e-12,
+	"n": 1e-9,
+	"u": 1e-6,
+	"µ": 1e-6,
+	"m": 1e-3,
+	"k": 1e3,
+	"K": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+	"T": 1e12,
+	"P": 1e15,
+	"E": 1e18,
+}
+
+// parseSuffixed parses a number followed by one of the given suffixes,
+// which gives the multiplier.
+func parseSuffixed(s string, suffixes map[string]float64) (float64, error) {
+	i := len(s)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if r >= '0' && r <= '9' || r == '.' {
+			break
+		}
+		i -= size
+	}
+	mult, ok := suffixes[strings.TrimSpace(s[i:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return v * mult, nil
+}
+
+// A scale is a suffix for values of at least size (in the base unit).
+type scale struct {
+	size   float64
+	suffix string
+}
+
+// Scales for formatting, from largest to smallest. Values smaller than all
+// of them use the last one.
+var (
+	durationScales = []scale{
+		{3600, "h"},
+		{60, "m"},
+		{1, "s"},
+		{1e-3, "ms"},
+		{1e-6, "µs"},
+		{1e-9, "ns"},
+	}
+	byteScales = []scale{
+		{1 << 60, "EiB"},
+		{1 << 50, "PiB"},
+		{1 << 40, "TiB"},
+		{1 << 30, "GiB"},
+		{1 << 20, "MiB"},
+		{1 << 10, "KiB"},
+		{1, "B"},
+	}
+	siScales = []scale{
+		{1e18, "E"},
+		{1e15, "P"},
+		{1e12, "T"},
+		{1e9, "G"},
+		{1e6, "M"},
+		{1e3, "k"},
+		{1, ""},
+		{1e-3, "m"},
+		{1e-6, "µ"},
+		{1e-9, "n"},
+	}
+)
+
+// format formats v, in the base unit of u, for people to read: scaled to a
+// suitable suffix and rounded to about three significant digits. Plain
+// numbers are printed in full.
+func (u unit) format(v float64) string {
+	var scales []scale
+	switch u {
+	case unitDuration:
+		scales = durationScales
+	case unitBytes:
+		scales = byteScales
+	case unitSI:
+		scales = siScales
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	sc := scales[len(scales)-1]
+	if v == 0 {
+		// Zero gets the base unit.
+		for _, s := range scales {
+			if s.size == 1 {
+				sc = s
+			}
+		}
+	}
+	for _, s := range scales {
+		if math.Abs(v) >= s.size {
+			sc = s
+			break
+		}
+	}
+	v /= sc.size
+	prec := 0
+	switch a := math.Abs(v); {
+	case a < 10:
+		prec = 2
+	case a < 100:
+		prec = 1
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s + sc.suffix
+}
+
+// A measure is a value in the unit of the input. It prints (using fmt) in
+// human-readable form; machine-readable formats use the plain value.
+type measure struct {
+	v float64
+	u unit
+}
+
+func (m measure) String() string { return m.u.format(m.v) }
+
+// unitValue gives v as a stat value in the unit u.
+func unitValue(v float64, u unit) interface{} {
+	if u == unitNone {
+		return v
+	}
+	return measure{v, u}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitParse(t *testing.T) {
+	for _, tt := range []struct {
+		u    unit
+		s    string
+		want float64 // NaN for an error
+	}{
+		{unitNone, "12.5", 12.5},
+		{unitNone, "5m", math.NaN()},
+
+		{unitDuration, "250", 250},
+		{unitDuration, "5m", 300},
+		{unitDuration, "5ms", 0.005},
+		{unitDuration, "1h30m", 5400},
+		{unitDuration, "3µs", 3e-6},
+		{unitDuration, "5KiB", math.NaN()},
+
+		{unitBytes, "340KiB", 340 << 10},
+		{unitBytes, "1.5GB", 1.5e9},
+		{unitBytes, "7 B", 7},
+		{unitBytes, "5m", math.NaN()},
+		{unitBytes, "5ms", math.NaN()},
+
+		{unitSI, "3k", 3000},
+		{unitSI, "2.5M", 2.5e6},
+		{unitSI, "5m", 0.005},
+		{unitSI, "4µ", 4e-6},
+		{unitSI, "5ms", math.NaN()},
+		{unitSI, "k", math.NaN()},
+	} {
+		got, err := tt.u.parse(tt.s)
+		if math.IsNaN(tt.want) {
+			if err == nil {
+				t.Errorf("unit %d: parse(%q): got %g; want error", tt.u, tt.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unit %d: parse(%q): %s", tt.u, tt.s, err)
+		} else if math.Abs(got-tt.want) > 1e-9*math.Abs(tt.want) {
+			t.Errorf("unit %d: parse(%q): got %g; want %g", tt.u, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestUnitParserAuto(t *testing.T) {
+	for _, tt := range []struct {
+		inputs []string
+		want   unit
+		bad    int // index of the first input that fails, or -1
+	}{
+		{[]string{"1", "2"}, unitNone, -1},
+		// Durations are tried first, so a bare m means minutes.
+		{[]string{"5", "5m", "5ms"}, unitDuration, -1},
+		{[]string{"5KiB", "3MB", "1"}, unitBytes, -1},
+		{[]string{"3k", "2M"}, unitSI, -1},
+		// Once the unit is chosen, other units' suffixes are rejected.
+		{[]string{"5ms", "5KiB"}, unitDuration, 1},
+		{[]string{"5KiB", "5ms"}, unitBytes, 1},
+		{[]string{"x"}, unitNone, 0},
+	} {
+		p, err := newUnitParser("auto")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad := -1
+		for i, s := range tt.inputs {
+			if _, err := p.parse(s); err != nil && bad < 0 {
+				bad = i
+			}
+		}
+		if p.unit != tt.want || bad != tt.bad {
+			t.Errorf("auto parsing %q: got unit %d, first failure %d; want %d, %d", tt.inputs, p.unit, bad, tt.want, tt.bad)
+		}
+	}
+}
+
+func TestUnitFormat(t *testing.T) {
+	for _, tt := range []struct {
+		u    unit
+		v    float64
+		want string
+	}{
+		{unitNone, 0.1234567, "0.1234567"},
+		{unitDuration, 0, "0s"},
+		{unitDuration, 0.005, "5ms"},
+		{unitDuration, 0.0123456, "12.3ms"},
+		{unitDuration, 300, "5m"},
+		{unitDuration, 5400, "1.5h"},
+		{unitDuration, 2e-9, "2ns"},
+		{unitDuration, -0.25, "-250ms"},
+		{unitBytes, 0, "0B"},
+		{unitBytes, 1536, "1.5KiB"},
+		{unitBytes, 123456789, "118MiB"},
+		{unitSI, 0, "0"},
+		{unitSI, 0.005, "5m"},
+		{unitSI, 2500, "2.5k"},
+		{unitSI, 42, "42"},
+		{unitBytes, math.NaN(), "NaN"},
+		{unitSI, math.Inf(1), "+Inf"},
+	} {
+		if got := tt.u.format(tt.v); got != tt.want {
+			t.Errorf("unit %d: format(%g): got %q; want %q", tt.u, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	si, err := newUnitParser("si")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := parseValue(" 5m ", si); err != nil || got != 0.005 {
+		t.Errorf("parseValue(5m) with -unit si: got %g, %v; want 0.005", got, err)
+	}
+	auto, err := newUnitParser("auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := parseValue("5m", auto); err != nil || got != 300 {
+		t.Errorf("parseValue(5m) with -unit auto: got %g, %v; want 300", got, err)
+	}
+	// Parsing a threshold doesn't fix the unit of the input.
+	if auto.unit != unitNone {
+		t.Errorf("parseValue chose unit %d for the input", auto.unit)
+	}
+	if _, err := parseValue("5m", &unitParser{}); err == nil {
+		t.Error("parseValue(5m) without a unit succeeded")
+	}
+}